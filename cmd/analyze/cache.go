@@ -25,16 +25,19 @@ var (
 )
 
 func snapshotFromModel(m model) historyEntry {
+	entries, largeFiles := m.unfilteredEntries()
 	return historyEntry{
 		Path:          m.path,
-		Entries:       cloneDirEntries(m.entries),
-		LargeFiles:    cloneFileEntries(m.largeFiles),
+		Entries:       cloneDirEntries(entries),
+		LargeFiles:    cloneFileEntries(largeFiles),
 		TotalSize:     m.totalSize,
+		Tree:          m.tree,
 		Selected:      m.selected,
 		EntryOffset:   m.offset,
 		LargeSelected: m.largeSelected,
 		LargeOffset:   m.largeOffset,
 		IsOverview:    m.isOverview,
+		SortOrder:     m.sortOrder,
 	}
 }
 
@@ -168,12 +171,12 @@ func loadOverviewCachedSize(path string) (int64, error) {
 	if snapshot, err := loadStoredOverviewSize(path); err == nil {
 		return snapshot, nil
 	}
-	cacheEntry, err := loadCacheFromDisk(path)
+	cacheEntry, err := loadCacheFromDisk(path, scanOptions{})
 	if err != nil {
 		return 0, err
 	}
-	_ = storeOverviewSize(path, cacheEntry.TotalSize)
-	return cacheEntry.TotalSize, nil
+	_ = storeOverviewSize(path, cacheEntry.Tree.Size)
+	return cacheEntry.Tree.Size, nil
 }
 
 func getCacheDir() (string, error) {
@@ -198,7 +201,7 @@ func getCachePath(path string) (string, error) {
 	return filepath.Join(cacheDir, filename), nil
 }
 
-func loadCacheFromDisk(path string) (*cacheEntry, error) {
+func loadCacheFromDisk(path string, opts scanOptions) (*cacheEntry, error) {
 	cachePath, err := getCachePath(path)
 	if err != nil {
 		return nil, err
@@ -215,6 +218,13 @@ func loadCacheFromDisk(path string) (*cacheEntry, error) {
 	if err := decoder.Decode(&entry); err != nil {
 		return nil, err
 	}
+	if entry.Tree == nil {
+		// Written by an older version that stored a single flat level
+		return nil, fmt.Errorf("cache has no tree")
+	}
+	if entry.Options != opts {
+		return nil, fmt.Errorf("cache was scanned with other mount options")
+	}
 
 	info, err := os.Stat(path)
 	if err != nil {
@@ -235,7 +245,7 @@ func loadCacheFromDisk(path string) (*cacheEntry, error) {
 	return &entry, nil
 }
 
-func saveCacheToDisk(path string, result scanResult) error {
+func saveCacheToDisk(path string, opts scanOptions, result scanResult) error {
 	cachePath, err := getCachePath(path)
 	if err != nil {
 		return err
@@ -246,12 +256,15 @@ func saveCacheToDisk(path string, result scanResult) error {
 		return err
 	}
 
+	if result.Tree == nil {
+		return fmt.Errorf("scan result has no tree")
+	}
+
 	entry := cacheEntry{
-		Entries:    result.Entries,
-		LargeFiles: result.LargeFiles,
-		TotalSize:  result.TotalSize,
-		ModTime:    info.ModTime(),
-		ScanTime:   time.Now(),
+		Tree:     result.Tree,
+		Options:  opts,
+		ModTime:  info.ModTime(),
+		ScanTime: time.Now(),
 	}
 
 	file, err := os.Create(cachePath)
@@ -260,6 +273,8 @@ func saveCacheToDisk(path string, result scanResult) error {
 	}
 	defer file.Close()
 
+	treeSaveMu.RLock()
+	defer treeSaveMu.RUnlock()
 	encoder := gob.NewEncoder(file)
 	return encoder.Encode(entry)
 }
@@ -319,7 +334,7 @@ func prefetchOverviewCache(ctx context.Context) {
 		default:
 		}
 
-		size, err := measureOverviewSize(path)
+		size, err := measureOverviewSize(ctx, path, sizeProgress{})
 		if err == nil && size > 0 {
 			_ = storeOverviewSize(path, size)
 		}
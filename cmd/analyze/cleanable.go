@@ -18,6 +18,12 @@ func isCleanableDir(path string) bool {
 		return false
 	}
 
+	// User rules: never-fold marks real data, skipped paths aren't shown
+	switch ruleActionFor(path, true) {
+	case ruleNeverFold, ruleSkip:
+		return false
+	}
+
 	baseName := filepath.Base(path)
 
 	// Only mark project dependencies and build outputs
@@ -25,6 +31,11 @@ func isCleanableDir(path string) bool {
 	if projectDependencyDirs[baseName] {
 		return true
 	}
+	for _, pattern := range projectDependencyPatterns {
+		if ok, _ := filepath.Match(pattern, baseName); ok {
+			return true
+		}
+	}
 
 	return false
 }
@@ -122,7 +133,12 @@ var projectDependencyDirs = map[string]bool{
 	".terraform": true, // Terraform plugins
 
 	// Linux-specific development directories
-	".cargo":     true, // Rust Cargo cache
-	".maven":     true, // Maven repository
-	".npm":       true, // npm cache (global)
+	".cargo": true, // Rust Cargo cache
+	".maven": true, // Maven repository
+	".npm":   true, // npm cache (global)
+}
+
+// projectDependencyPatterns match generated directories by glob
+var projectDependencyPatterns = []string{
+	"*.egg-info", // setuptools metadata
 }
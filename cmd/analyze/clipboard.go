@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/naiplawan/marmot/internal/clipboard"
+)
+
+type copiedMsg struct {
+	path   string
+	method string
+	err    error
+}
+
+// copyPathCmd puts path on the clipboard off the UI goroutine, since the
+// clipboard tools may wait on the display server.
+func copyPathCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		method, err := clipboard.Copy(path)
+		return copiedMsg{path: path, method: method, err: err}
+	}
+}
+
+// copySelected copies the path of the entry under the cursor, or of the
+// current directory when dir is set.
+func (m *model) copySelected(dir bool) tea.Cmd {
+	path := m.path
+	if !dir {
+		_, selected, ok := m.selectedItem()
+		if !ok {
+			return nil
+		}
+		path = selected
+	}
+	if path == "" {
+		return nil
+	}
+	return copyPathCmd(path)
+}
+
+func (m *model) handleCopied(msg copiedMsg) {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Cannot copy %s: %v", displayPath(msg.path), msg.err)
+		return
+	}
+	m.status = fmt.Sprintf("Copied %s (%s)", displayPath(msg.path), msg.method)
+}
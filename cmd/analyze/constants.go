@@ -3,8 +3,8 @@ package main
 import "time"
 
 const (
-	maxEntries            = 30
-	maxLargeFiles         = 30
+	defaultCompactEntries = 30 // Rows of the compact view before "other items" (-compact-entries)
+	defaultLargeFiles     = 30 // Large files kept per directory (-max-large-files)
 	barWidth              = 24
 	minLargeFileSize      = 100 << 20          // 100 MB
 	defaultViewport       = 12                 // Default viewport when terminal height is unknown
@@ -12,9 +12,14 @@ const (
 	overviewCacheFile     = "overview_sizes.json"
 	duTimeout             = 60 * time.Second // Increased for large directories
 	mdlsTimeout           = 5 * time.Second
+	locateTimeout         = 15 * time.Second
+	largeIndexStaleAge    = 48 * time.Hour   // updatedb normally runs daily
 	maxConcurrentOverview = 3                // Scan up to 3 overview dirs concurrently
 	batchUpdateSize       = 100              // Batch atomic updates every N items
 	cacheModTimeGrace     = 30 * time.Minute // Ignore minor directory mtime bumps
+	treeCollapseSize      = 1 << 20          // Subtrees below 1 MB keep only their aggregates
+	maxTreeChildren       = 256              // Children kept per nested tree node
+	maxUnreadableListed   = 100              // Unreadable directories named in a headless report
 
 	// Worker pool configuration
 	minWorkers         = 8                // Minimum workers for better I/O throughput
@@ -22,6 +27,17 @@ const (
 	cpuMultiplier      = 2                // Worker multiplier per CPU core for I/O-bound operations
 	maxDirWorkers      = 16               // Maximum concurrent subdirectory scans
 	openCommandTimeout = 10 * time.Second // Timeout for open/reveal commands
+	maxDeleteWorkers   = 4                // Concurrent targets in a batch delete
+	maxFailureLines    = 3                // Per-item delete failures listed under the footer
+	maxPreviewLargest  = 5                // Largest files listed in the delete preview
+	maxPreviewSamples  = 3                // Example paths per delete preview warning
+	previewTimeout     = 10 * time.Second // Delete previews of huge trees stop early
+	maxWatchDirs       = 8192             // Directories watched at most in watch mode
+	watchDebounce      = 500 * time.Millisecond
+	doubleClickTime    = 400 * time.Millisecond // Two clicks on a row within this enter it
+	wheelStep          = 3                      // Rows scrolled per wheel notch
+	openerWaitDelay    = 500 * time.Millisecond // How long stderr is read after an opener exits
+	openerStderrLimit  = 4096                   // Bytes of opener error output kept for the status line
 )
 
 var foldDirs = map[string]bool{
@@ -67,7 +83,6 @@ var foldDirs = map[string]bool{
 	".tox":          true,
 	"site-packages": true,
 	".eggs":         true,
-	"*.egg-info":    true,
 	".pyenv":        true,
 	".poetry":       true,
 	".pip":          true,
@@ -164,6 +179,11 @@ var foldDirs = map[string]bool{
 	"temp":       true,
 }
 
+// foldDirPatterns are folded by glob, for names foldDirs can't list literally.
+var foldDirPatterns = []string{
+	"*.egg-info",
+}
+
 var skipSystemDirs = map[string]bool{
 	// Common system directories (both platforms)
 	"dev":                     true,
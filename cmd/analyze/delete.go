@@ -4,21 +4,85 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func deletePathCmd(path string, counter *int64) tea.Cmd {
+// deleteItemResult is the outcome for one target of a batch delete.
+type deleteItemResult struct {
+	path      string
+	trashedTo string
+	count     int64
+	err       error
+}
+
+// deleteTargetsCmd moves every target to the trash, or removes them for good
+// when permanent is set. Targets are processed concurrently and counter
+// aggregates removed items across all of them.
+func deleteTargetsCmd(targets []dirEntry, counter *int64, permanent bool) tea.Cmd {
 	return func() tea.Msg {
-		count, err := deletePathWithProgress(path, counter)
-		return deleteProgressMsg{
-			done:  true,
-			err:   err,
-			count: count,
-			path:  path,
+		results := make([]deleteItemResult, len(targets))
+		sem := make(chan struct{}, maxDeleteWorkers)
+		var wg sync.WaitGroup
+		for i, target := range targets {
+			wg.Add(1)
+			go func(i int, target dirEntry) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				results[i] = deleteTarget(target, counter, permanent)
+			}(i, target)
+		}
+		wg.Wait()
+
+		msg := deleteProgressMsg{done: true, results: results}
+		for _, result := range results {
+			msg.count += result.count
+			if result.err != nil && msg.err == nil {
+				msg.err = result.err
+			}
+		}
+		return msg
+	}
+}
+
+// deleteTarget deletes a single target and records it in the deletion journal.
+func deleteTarget(target dirEntry, counter *int64, permanent bool) deleteItemResult {
+	result := deleteItemResult{path: target.Path}
+	if err := validatePathForDeletion(target.Path); err != nil {
+		result.err = err
+		return result
+	}
+	if permanent {
+		result.count, result.err = deletePathWithProgress(target.Path, counter)
+	} else {
+		result.trashedTo, result.err = moveToTrash(target.Path)
+		if result.err == nil {
+			result.count = 1
+			if counter != nil {
+				atomic.AddInt64(counter, 1)
+			}
+		}
+	}
+
+	// Permanent deletes that failed halfway still removed something worth recording
+	if result.err == nil || (permanent && result.count > 0) {
+		items := target.FileCount
+		if items < result.count {
+			items = result.count
 		}
+		_ = appendDeleteJournal(deletionRecord{
+			Path:      target.Path,
+			Size:      target.Size,
+			Items:     items,
+			DeletedAt: time.Now(),
+			TrashedTo: result.trashedTo,
+		})
 	}
+	return result
 }
 
 func deletePathWithProgress(root string, counter *int64) (int64, error) {
@@ -45,7 +109,7 @@ func deletePathWithProgress(root string, counter *int64) (int64, error) {
 			if removeErr := os.Remove(path); removeErr == nil {
 				count++
 				if counter != nil {
-					atomic.StoreInt64(counter, count)
+					atomic.AddInt64(counter, 1)
 				}
 			} else if firstErr == nil {
 				// Record first deletion error
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	detailPaneWidth    = 40   // Columns of the details pane, separator included
+	minDetailListWidth = 86   // A listing row without hints; narrower, the pane replaces the listing
+	detailPreviewBytes = 4096 // Read from a file to decide whether it is text
+	detailPreviewLines = 8    // First lines of a text file shown
+	detailTopTypes     = 5    // Largest extensions shown for a directory
+
+	defaultDetailListWidth = 80 // Listing width when the terminal width is unknown
+)
+
+// entryDetail is what stat says about an entry, for the details pane.
+// Directory contents come from the scanned tree instead.
+type entryDetail struct {
+	Path       string
+	Err        error
+	Kind       string
+	Target     string // Where a symlink points
+	Owner      string
+	Group      string
+	Mode       fs.FileMode
+	ModTime    time.Time
+	AccessTime time.Time
+	ChangeTime time.Time
+	Apparent   int64 // Size as reported by stat
+	OnDisk     int64 // Allocated blocks
+	Links      uint64
+	FSType     string
+	MountPoint string
+	Sparse     bool     // Fewer blocks allocated than the size needs
+	Preview    []string // First lines of a text file
+}
+
+type detailMsg struct {
+	detail entryDetail
+}
+
+// loadDetailCmd stats path off the UI goroutine; user lookups and reading a
+// file on a slow disk can take a moment.
+func loadDetailCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		return detailMsg{detail: readEntryDetail(path)}
+	}
+}
+
+func readEntryDetail(path string) entryDetail {
+	detail := entryDetail{Path: path}
+	info, err := os.Lstat(path)
+	if err != nil {
+		detail.Err = err
+		return detail
+	}
+
+	detail.Mode = info.Mode()
+	detail.ModTime = info.ModTime()
+	detail.Apparent = info.Size()
+	detail.OnDisk = info.Size()
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		detail.Kind = "Symlink"
+		detail.Target, _ = os.Readlink(path)
+	case info.IsDir():
+		detail.Kind = "Directory"
+	case info.Mode().IsRegular():
+		detail.Kind = "File"
+	default:
+		detail.Kind = "Special file"
+	}
+
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		detail.Owner = lookupUser(stat.Uid)
+		detail.Group = lookupGroup(stat.Gid)
+		detail.Links = uint64(stat.Nlink)
+		detail.OnDisk = stat.Blocks * 512
+		detail.AccessTime, detail.ChangeTime = statTimes(stat)
+		// Blocks are allocated a filesystem block at a time, so a file is
+		// only sparse when a whole block or more is missing
+		detail.Sparse = info.Mode().IsRegular() && detail.OnDisk+int64(stat.Blksize) <= detail.Apparent
+	}
+	if mount, ok := mountContaining(path); ok {
+		detail.FSType = mount.FSType
+		detail.MountPoint = mount.Point
+	}
+	// A file without allocated blocks is empty, sparse or a cloud placeholder
+	// that reading would download
+	if info.Mode().IsRegular() && detail.OnDisk > 0 {
+		detail.Preview = textPreview(path)
+	}
+	return detail
+}
+
+func lookupUser(uid uint32) string {
+	id := strconv.FormatUint(uint64(uid), 10)
+	if u, err := user.LookupId(id); err == nil {
+		return u.Username
+	}
+	return id
+}
+
+func lookupGroup(gid uint32) string {
+	id := strconv.FormatUint(uint64(gid), 10)
+	if g, err := user.LookupGroupId(id); err == nil {
+		return g.Name
+	}
+	return id
+}
+
+// textPreview returns the first lines of a text file, nil for anything that
+// looks binary.
+func textPreview(path string) []string {
+	file, err := openNoAtime(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	buf := make([]byte, detailPreviewBytes)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil
+	}
+	buf = buf[:n]
+	if bytes.IndexByte(buf, 0) >= 0 {
+		return nil
+	}
+	if n == detailPreviewBytes {
+		// The read may have cut a character in half
+		for i := 0; i < utf8.UTFMax && !utf8.Valid(buf); i++ {
+			buf = buf[:len(buf)-1]
+		}
+	}
+	if !utf8.Valid(buf) {
+		return nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(buf), "\r\n", "\n"), "\n")
+	if len(lines) > detailPreviewLines {
+		lines = lines[:detailPreviewLines]
+	}
+	for i, line := range lines {
+		lines[i] = strings.Map(func(r rune) rune {
+			if r == '\t' {
+				return ' '
+			}
+			if r < ' ' {
+				return -1
+			}
+			return r
+		}, line)
+	}
+	return lines
+}
+
+// syncDetail loads the details of the entry under the cursor once it changes.
+func (m *model) syncDetail() tea.Cmd {
+	if !m.showDetail {
+		return nil
+	}
+	_, path, ok := m.selectedItem()
+	if !ok || path == m.detailPath {
+		return nil
+	}
+	m.detailPath = path
+	return loadDetailCmd(path)
+}
+
+// detailLines renders the details pane, width columns wide.
+func (m model) detailLines(width int) []string {
+	var lines []string
+	add := func(format string, args ...any) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+	field := func(label, value string) {
+		add("%s%-9s%s %s", colorGray, label, colorReset, truncateMiddle(value, width-10))
+	}
+
+	add("%sDetails%s", colorPurpleBold, colorReset)
+	name, path, ok := m.selectedItem()
+	if !ok {
+		add("%sNothing selected%s", colorGray, colorReset)
+		return lines
+	}
+	add("%s%s%s", colorCyan, truncateMiddle(trimName(name), width), colorReset)
+	add("")
+	detail := m.detail
+	if detail.Path != path {
+		add("%sLoading...%s", colorGray, colorReset)
+		return lines
+	}
+	if detail.Err != nil {
+		add("%s%s%s", colorRed, truncateMiddle(detail.Err.Error(), width), colorReset)
+		return lines
+	}
+
+	kind := detail.Kind
+	if detail.Target != "" {
+		kind += " → " + detail.Target
+	}
+	field("Type", kind)
+	field("Owner", detail.Owner+":"+detail.Group)
+	field("Mode", fmt.Sprintf("%s (%04o)", detail.Mode, detail.Mode.Perm()))
+	field("Modified", formatDetailTime(detail.ModTime))
+	field("Accessed", formatDetailTime(detail.AccessTime))
+	field("Changed", formatDetailTime(detail.ChangeTime))
+	field("Links", strconv.FormatUint(detail.Links, 10))
+	if detail.FSType != "" {
+		field("FS", fmt.Sprintf("%s on %s", detail.FSType, detail.MountPoint))
+	}
+
+	node := m.tree.find(path)
+	if detail.Kind == "Directory" {
+		if node == nil {
+			field("Size", "not scanned")
+			return lines
+		}
+		field("Size", humanizeBytes(node.Size)+" on disk")
+		field("Contents", fmt.Sprintf("%s files, %s dirs", formatNumber(node.FileCount), formatNumber(node.DirCount)))
+		if len(node.Types) > 0 {
+			add("")
+			add("%sLargest types%s", colorGray, colorReset)
+			for i, total := range node.Types {
+				if i == detailTopTypes {
+					break
+				}
+				add("  %-12s %10s  %s files", truncateMiddle(total.Ext, 12), humanizeBytes(total.Size), formatNumber(total.Files))
+			}
+		}
+		return lines
+	}
+
+	field("Size", fmt.Sprintf("%s on disk", humanizeBytes(detail.OnDisk)))
+	field("Apparent", humanizeBytes(detail.Apparent))
+	if detail.Kind == "File" {
+		sparse := "no"
+		if detail.Sparse {
+			sparse = "yes"
+		}
+		field("Sparse", sparse)
+	}
+	if len(detail.Preview) > 0 {
+		add("")
+		for _, line := range detail.Preview {
+			add("%s%s%s", colorGray, truncateMiddle(line, width), colorReset)
+		}
+	}
+	return lines
+}
+
+func formatDetailTime(t time.Time) string {
+	if t.IsZero() || t.Unix() == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s (%s)", t.Format("2006-01-02 15:04"), formatAge(t))
+}
+
+// detailReplacesList reports whether the terminal is too narrow for the
+// details pane next to the listing, so it is shown instead.
+func (m model) detailReplacesList() bool {
+	return m.showDetail && m.width > 0 && m.width-detailPaneWidth < minDetailListWidth
+}
+
+// withDetailPane puts the details pane to the right of the rendered listing,
+// cutting off listing lines that would run into it.
+func (m model) withDetailPane(screen string) string {
+	pane := m.detailLines(detailPaneWidth - 2)
+	if m.height > 2 && len(pane) > m.height-2 {
+		pane = pane[:m.height-2]
+	}
+	if m.detailReplacesList() {
+		// No room next to the listing
+		return "\n" + strings.Join(pane, "\n") + "\n\n" + colorGray + "i Close details" + colorReset + "\n"
+	}
+
+	listWidth := defaultDetailListWidth
+	if m.width > 0 {
+		listWidth = m.width - detailPaneWidth
+	}
+	cut := lipgloss.NewStyle().MaxWidth(listWidth)
+	left := strings.Split(strings.TrimSuffix(screen, "\n"), "\n")
+	for len(left) < len(pane)+1 {
+		left = append(left, "")
+	}
+	var b strings.Builder
+	for i, line := range left {
+		line = cut.Render(line)
+		b.WriteString(line)
+		// The pane starts on the header line, below the blank first line
+		if i >= 1 && i-1 < len(pane) {
+			b.WriteString(strings.Repeat(" ", max(listWidth-lipgloss.Width(line), 0)))
+			fmt.Fprintf(&b, "%s│%s %s", colorGray, colorReset, pane[i-1])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// openNoAtime opens path for reading. Only Linux can leave the access time
+// alone.
+func openNoAtime(path string) (*os.File, error) {
+	return os.Open(path)
+}
+
+// statTimes returns the access and status change times of stat.
+func statTimes(stat *syscall.Stat_t) (access, change time.Time) {
+	return time.Unix(stat.Atimespec.Sec, stat.Atimespec.Nsec), time.Unix(stat.Ctimespec.Sec, stat.Ctimespec.Nsec)
+}
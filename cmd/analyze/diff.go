@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diffState is how an entry changed since the snapshot.
+type diffState int
+
+const (
+	diffSame diffState = iota
+	diffGrown
+	diffShrunk
+	diffNew     // Not in the snapshot
+	diffGone    // In the snapshot, deleted since
+	diffUnknown // Left out of a truncated snapshot listing, its old size is unknown
+)
+
+// diffRow compares one child of the diffed directory with the snapshot.
+type diffRow struct {
+	Name  string
+	Path  string
+	IsDir bool
+	Old   int64
+	New   int64
+	State diffState
+}
+
+func (r diffRow) delta() int64 {
+	if r.State == diffUnknown {
+		return 0
+	}
+	return r.New - r.Old
+}
+
+// compareSizes classifies a size change between the snapshot and now.
+func compareSizes(old, new int64) diffState {
+	switch {
+	case new > old:
+		return diffGrown
+	case new < old:
+		return diffShrunk
+	default:
+		return diffSame
+	}
+}
+
+// buildDiffRows compares the children of current with those of the snapshot
+// node base, biggest growth first and vanished items last. Either listing may
+// be truncated to its largest children, so a missing child is not
+// necessarily new or gone.
+func buildDiffRows(base, current *dirNode) []diffRow {
+	old := make(map[string]*dirNode, len(base.Children))
+	for _, child := range base.Children {
+		old[child.Path] = child
+	}
+
+	var rows []diffRow
+	for _, child := range current.Children {
+		row := diffRow{Name: child.Name, Path: child.Path, IsDir: child.IsDir, New: child.Size, State: diffNew}
+		if before, ok := old[child.Path]; ok {
+			row.Old = before.Size
+			row.State = compareSizes(row.Old, row.New)
+			delete(old, child.Path)
+		} else if base.Truncated {
+			row.State = diffUnknown
+		}
+		rows = append(rows, row)
+	}
+	for path, before := range old {
+		if current.Truncated {
+			if _, err := os.Lstat(path); err == nil {
+				continue
+			}
+		}
+		rows = append(rows, diffRow{Name: before.Name, Path: path, IsDir: before.IsDir, Old: before.Size, State: diffGone})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if di, dj := rows[i].delta(), rows[j].delta(); di != dj {
+			return di > dj
+		}
+		return rows[i].New > rows[j].New
+	})
+	return rows
+}
+
+// openDiff shows the changes below path against the loaded snapshot. It
+// returns false with m.status set when either scan lacks the details.
+func (m *model) openDiff(path string) bool {
+	base := m.diffBase.find(path)
+	current := m.tree.find(path)
+	switch {
+	case !current.hasListing():
+		m.status = fmt.Sprintf("%s has no details in the current scan, open it first", displayPath(path))
+		return false
+	case !base.hasListing():
+		m.status = fmt.Sprintf("%s was not scanned in detail on %s", displayPath(path), m.diffSnapshot.ScanTime.Format("Jan 2 15:04"))
+		return false
+	}
+	m.diffPath = path
+	m.diffRows = buildDiffRows(base, current)
+	m.diffOld = base.Size
+	m.diffNew = current.Size
+	m.diffSelected = 0
+	m.diffOffset = 0
+	m.status = ""
+	return true
+}
+
+// updateSnapshotKey handles keys while the list of snapshots is shown.
+func (m model) updateSnapshotKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "c", "b", "left", "h":
+		m.showSnapshots = false
+	case "up", "k":
+		if m.snapshotSelected > 0 {
+			m.snapshotSelected--
+		}
+		m.snapshotOffset = clampListOffset(m.snapshotSelected, m.snapshotOffset, m.height)
+	case "down", "j":
+		if m.snapshotSelected < len(m.snapshots)-1 {
+			m.snapshotSelected++
+		}
+		m.snapshotOffset = clampListOffset(m.snapshotSelected, m.snapshotOffset, m.height)
+	case "enter", "right", "l":
+		if len(m.snapshots) == 0 {
+			return m, nil
+		}
+		snapshot := m.snapshots[m.snapshotSelected]
+		m.status = fmt.Sprintf("Loading scan from %s...", snapshot.ScanTime.Format("Jan 2 15:04"))
+		return m, loadSnapshotTreeCmd(snapshot)
+	}
+	return m, nil
+}
+
+// updateDiffKey handles keys while the diff against a snapshot is shown.
+func (m model) updateDiffKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "c":
+		m.showDiff = false
+		m.diffBase = nil
+		m.diffRows = nil
+	case "up", "k":
+		if m.diffSelected > 0 {
+			m.diffSelected--
+		}
+		m.diffOffset = clampListOffset(m.diffSelected, m.diffOffset, m.height)
+	case "down", "j":
+		if m.diffSelected < len(m.diffRows)-1 {
+			m.diffSelected++
+		}
+		m.diffOffset = clampListOffset(m.diffSelected, m.diffOffset, m.height)
+	case "enter", "right", "l":
+		if len(m.diffRows) == 0 {
+			return m, nil
+		}
+		row := m.diffRows[m.diffSelected]
+		if !row.IsDir || row.State == diffGone || row.State == diffNew || row.State == diffUnknown {
+			return m, nil
+		}
+		parent := m.diffPath
+		if m.openDiff(row.Path) {
+			m.diffStack = append(m.diffStack, parent)
+		}
+	case "b", "left", "h":
+		if len(m.diffStack) == 0 {
+			m.showDiff = false
+			m.diffBase = nil
+			m.diffRows = nil
+			return m, nil
+		}
+		parent := m.diffStack[len(m.diffStack)-1]
+		m.diffStack = m.diffStack[:len(m.diffStack)-1]
+		child := m.diffPath
+		m.openDiff(parent)
+		for i, row := range m.diffRows {
+			if row.Path == child {
+				m.diffSelected = i
+				m.diffOffset = clampListOffset(i, 0, m.height)
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
+// clampListOffset scrolls a full-screen list so selected stays visible.
+func clampListOffset(selected, offset, height int) int {
+	return clampOffset(selected, offset, calculateViewport(height, true))
+}
+
+// clampOffset scrolls a list showing viewport rows so selected stays visible.
+func clampOffset(selected, offset, viewport int) int {
+	if selected < offset {
+		offset = selected
+	}
+	if selected >= offset+viewport {
+		offset = selected - viewport + 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return offset
+}
+
+// diffLabel renders the change column of a row.
+func (r diffRow) diffLabel() (string, string) {
+	switch r.State {
+	case diffNew:
+		return "new", colorYellow
+	case diffGone:
+		return "gone", colorGray
+	case diffUnknown:
+		return "not in snapshot listing", colorGray
+	case diffGrown:
+		return "+" + humanizeBytes(r.delta()), colorRed
+	case diffShrunk:
+		return "-" + humanizeBytes(-r.delta()), colorGreen
+	default:
+		return "=", colorGray
+	}
+}
+
+// snapshotScope names the parent directory a snapshot was taken of, "" when
+// it is a snapshot of path itself.
+func snapshotScope(snapshot scanSnapshot, path string) string {
+	if snapshot.Path == path {
+		return ""
+	}
+	return "scan of " + displayPath(snapshot.Path)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// exportSchemaVersion is bumped whenever a field is renamed or removed from the
+// headless report. Adding fields does not change the version.
+const exportSchemaVersion = 1
+
+// Exit codes used by the headless (--json / --csv) mode.
+const (
+	exitOK         = 0
+	exitScanError  = 1
+	exitUsage      = 2
+	exitIncomplete = 3 // The report was written, but some directories could not be read
+)
+
+type exportFormat int
+
+const (
+	exportNone exportFormat = iota
+	exportJSON
+	exportCSV
+)
+
+// exportReport is the stable document written by `analyze-go --json <path>`.
+type exportReport struct {
+	SchemaVersion int           `json:"schema_version"`
+	Path          string        `json:"path"`
+	TotalSize     int64         `json:"total_size"`
+	HardlinkBytes int64         `json:"hardlink_dedup_bytes"`
+	FilesScanned  int64         `json:"files_scanned"`
+	DirsScanned   int64         `json:"dirs_scanned"`
+	DurationMs    int64         `json:"duration_ms"`
+	ScannedAt     time.Time     `json:"scanned_at"`
+	ReadErrors    int64         `json:"read_errors"` // Directories that could not be read
+	Unreadable    []string      `json:"unreadable"`  // The first of them
+	Entries       []exportEntry `json:"entries"`
+	LargeFiles    []exportFile  `json:"large_files"`
+}
+
+type exportEntry struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	IsDir     bool   `json:"is_dir"`
+	IsSymlink bool   `json:"is_symlink"`
+	FileCount int64  `json:"file_count"`
+}
+
+type exportFile struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// csvHeader is the column layout of `analyze-go --csv <path>`. The first data
+// row has kind "root" and carries the totals; "entry", "large_file" and
+// "unreadable" rows follow. New columns are only ever appended.
+var csvHeader = []string{"kind", "name", "path", "size", "is_dir", "files_scanned", "dirs_scanned", "duration_ms", "file_count", "is_symlink", "read_errors"}
+
+// runHeadless scans path to completion without the TUI and writes the result
+// to out. It returns the process exit code.
+func runHeadless(ctx context.Context, path string, format exportFormat, opts scanOptions, out, errOut io.Writer) int {
+	var filesScanned, dirsScanned, bytesScanned int64
+
+	start := time.Now()
+	result, err := scanPathConcurrent(ctx, path, opts, &filesScanned, &dirsScanned, &bytesScanned, nil)
+	if err != nil {
+		fmt.Fprintf(errOut, "scan failed: %v\n", err)
+		return exitScanError
+	}
+
+	// Scheduled reports build up the history the TUI compares against
+	_ = saveScanSnapshot(path, opts, result)
+
+	report := buildExportReport(path, result, filesScanned, dirsScanned, time.Since(start))
+	report.ScannedAt = start
+
+	switch format {
+	case exportCSV:
+		err = writeExportCSV(out, report)
+	default:
+		err = writeExportJSON(out, report)
+	}
+	if err != nil {
+		fmt.Fprintf(errOut, "write report: %v\n", err)
+		return exitScanError
+	}
+	if report.ReadErrors > 0 {
+		fmt.Fprintf(errOut, "%d directories could not be read, their sizes are missing\n", report.ReadErrors)
+		return exitIncomplete
+	}
+	return exitOK
+}
+
+func buildExportReport(path string, result scanResult, files, dirs int64, elapsed time.Duration) exportReport {
+	report := exportReport{
+		SchemaVersion: exportSchemaVersion,
+		Path:          path,
+		TotalSize:     result.TotalSize,
+		FilesScanned:  files,
+		DirsScanned:   dirs,
+		DurationMs:    elapsed.Milliseconds(),
+		ReadErrors:    result.ReadErrors,
+		Unreadable:    append([]string{}, result.Unreadable...),
+		Entries:       make([]exportEntry, 0, len(result.Entries)),
+		LargeFiles:    make([]exportFile, 0, len(result.LargeFiles)),
+	}
+	if result.Tree != nil {
+		report.HardlinkBytes = result.Tree.Deduped
+	}
+	for _, entry := range result.Entries {
+		report.Entries = append(report.Entries, exportEntry{
+			// The listing decorates names, e.g. symlinks with an arrow
+			Name:      filepath.Base(entry.Path),
+			Path:      entry.Path,
+			Size:      entry.Size,
+			IsDir:     entry.IsDir,
+			IsSymlink: entry.IsSymlink,
+			FileCount: entry.FileCount,
+		})
+	}
+	for _, file := range result.LargeFiles {
+		report.LargeFiles = append(report.LargeFiles, exportFile{
+			Name: file.Name,
+			Path: file.Path,
+			Size: file.Size,
+		})
+	}
+	return report
+}
+
+func writeExportJSON(w io.Writer, report exportReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func writeExportCSV(w io.Writer, report exportReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	root := []string{
+		"root", "", report.Path,
+		strconv.FormatInt(report.TotalSize, 10),
+		"true",
+		strconv.FormatInt(report.FilesScanned, 10),
+		strconv.FormatInt(report.DirsScanned, 10),
+		strconv.FormatInt(report.DurationMs, 10),
+		"", "",
+		strconv.FormatInt(report.ReadErrors, 10),
+	}
+	if err := writer.Write(root); err != nil {
+		return err
+	}
+
+	for _, entry := range report.Entries {
+		row := []string{
+			"entry", entry.Name, entry.Path,
+			strconv.FormatInt(entry.Size, 10),
+			strconv.FormatBool(entry.IsDir),
+			"", "", "",
+			strconv.FormatInt(entry.FileCount, 10),
+			strconv.FormatBool(entry.IsSymlink),
+			"",
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, file := range report.LargeFiles {
+		row := []string{
+			"large_file", file.Name, file.Path,
+			strconv.FormatInt(file.Size, 10),
+			"false",
+			"", "", "",
+			"1", "false", "",
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	for _, path := range report.Unreadable {
+		row := []string{"unreadable", filepath.Base(path), path, "", "true", "", "", "", "", "", ""}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
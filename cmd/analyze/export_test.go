@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func exportFixture(t *testing.T, format exportFormat) (string, *bytes.Buffer, int) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	resetRules()
+	t.Cleanup(resetRules)
+
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"docs/a.txt": "a",
+		"docs/b.txt": "b",
+		"c.txt":      "c",
+	})
+	if err := os.Symlink("c.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	code := runHeadless(context.Background(), root, format, scanOptions{}, &out, io.Discard)
+	return root, &out, code
+}
+
+func TestExportJSON(t *testing.T) {
+	_, out, code := exportFixture(t, exportJSON)
+	if code != exitOK {
+		t.Fatalf("exit code %d", code)
+	}
+	var report exportReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	entries := make(map[string]exportEntry)
+	for _, entry := range report.Entries {
+		entries[entry.Name] = entry
+	}
+	if link, ok := entries["link"]; !ok || !link.IsSymlink || link.FileCount != 1 {
+		t.Errorf("symlink exported as %+v, want name link", report.Entries)
+	}
+	if docs := entries["docs"]; !docs.IsDir || docs.FileCount != 2 {
+		t.Errorf("docs exported as %+v", docs)
+	}
+	if report.ReadErrors != 0 || report.Unreadable == nil {
+		t.Errorf("read_errors = %d, unreadable = %v", report.ReadErrors, report.Unreadable)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	_, out, code := exportFixture(t, exportCSV)
+	if code != exitOK {
+		t.Fatalf("exit code %d", code)
+	}
+	rows, err := csv.NewReader(out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1+1+3 {
+		t.Fatalf("got %d rows, want header, root and 3 entries", len(rows))
+	}
+	column := make(map[string]int)
+	for i, name := range rows[0] {
+		column[name] = i
+	}
+	for _, row := range rows[2:] {
+		if row[column["name"]] == "docs" && row[column["file_count"]] != "2" {
+			t.Errorf("docs row %v has the wrong file_count", row)
+		}
+		if row[column["name"]] == "link" && row[column["is_symlink"]] != "true" {
+			t.Errorf("link row %v is not marked as a symlink", row)
+		}
+	}
+}
+
+func TestExportUnreadable(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	t.Setenv("HOME", t.TempDir())
+	resetRules()
+	t.Cleanup(resetRules)
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{"locked/secret.txt": "s", "open.txt": "o"})
+	locked := filepath.Join(root, "locked")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(locked, 0o755) })
+
+	var out bytes.Buffer
+	if code := runHeadless(context.Background(), root, exportJSON, scanOptions{}, &out, io.Discard); code != exitIncomplete {
+		t.Errorf("exit code %d, want %d", code, exitIncomplete)
+	}
+	var report exportReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.ReadErrors != 1 || len(report.Unreadable) != 1 || report.Unreadable[0] != locked {
+		t.Errorf("read_errors = %d, unreadable = %v", report.ReadErrors, report.Unreadable)
+	}
+}
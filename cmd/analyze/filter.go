@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type filterMode int
+
+const (
+	filterSubstring filterMode = iota
+	filterGlob
+	filterFuzzy
+)
+
+func (f filterMode) String() string {
+	switch f {
+	case filterGlob:
+		return "glob"
+	case filterFuzzy:
+		return "fuzzy"
+	default:
+		return "substring"
+	}
+}
+
+// matchesFilter reports whether name matches query. Matching is case-insensitive.
+func matchesFilter(name, query string, mode filterMode) bool {
+	if query == "" {
+		return true
+	}
+	name = strings.ToLower(strings.TrimSuffix(name, " →"))
+	query = strings.ToLower(query)
+
+	switch mode {
+	case filterGlob:
+		ok, err := filepath.Match(query, name)
+		return err == nil && ok
+	case filterFuzzy:
+		return fuzzyMatch(name, query)
+	default:
+		return strings.Contains(name, query)
+	}
+}
+
+// fuzzyMatch reports whether every rune of query appears in name, in order.
+func fuzzyMatch(name, query string) bool {
+	remaining := []rune(query)
+	for _, r := range name {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
+
+// startFilter opens the filter prompt, keeping the unfiltered lists aside.
+func (m *model) startFilter() {
+	if !m.filterOn {
+		m.filterOn = true
+		m.filterEntries = m.entries
+		m.filterLargeFiles = m.largeFiles
+		m.filterQuery = ""
+	}
+	m.filterTyping = true
+}
+
+// applyFilter rebuilds the visible lists from the unfiltered ones, keeping the
+// selected rows when they still match.
+func (m *model) applyFilter() {
+	if !m.filterOn {
+		return
+	}
+	var selectedPath, largeSelectedPath string
+	if m.selected >= 0 && m.selected < len(m.entries) {
+		selectedPath = m.entries[m.selected].Path
+	}
+	if m.largeSelected >= 0 && m.largeSelected < len(m.largeFiles) {
+		largeSelectedPath = m.largeFiles[m.largeSelected].Path
+	}
+
+	m.entries = make([]dirEntry, 0, len(m.filterEntries))
+	m.selected = 0
+	for _, entry := range m.filterEntries {
+		if matchesFilter(entry.Name, m.filterQuery, m.filterMode) {
+			if entry.Path == selectedPath {
+				m.selected = len(m.entries)
+			}
+			m.entries = append(m.entries, entry)
+		}
+	}
+	m.largeFiles = make([]fileEntry, 0, len(m.filterLargeFiles))
+	m.largeSelected = 0
+	for _, file := range m.filterLargeFiles {
+		if matchesFilter(file.Name, m.filterQuery, m.filterMode) {
+			if file.Path == largeSelectedPath {
+				m.largeSelected = len(m.largeFiles)
+			}
+			m.largeFiles = append(m.largeFiles, file)
+		}
+	}
+	m.clampEntrySelection()
+	m.clampLargeSelection()
+}
+
+// clearFilter restores the unfiltered lists and keeps the selection on the
+// same rows.
+func (m *model) clearFilter() {
+	if !m.filterOn {
+		return
+	}
+	m.filterQuery = ""
+	m.applyFilter()
+	m.filterOn = false
+	m.filterTyping = false
+	m.filterEntries = nil
+	m.filterLargeFiles = nil
+}
+
+// setFilteredListing replaces the unfiltered lists (after a rescan of the
+// same directory) and re-applies the active filter.
+func (m *model) setFilteredListing(entries []dirEntry, largeFiles []fileEntry) {
+	m.filterEntries = entries
+	m.filterLargeFiles = largeFiles
+	m.entries = entries
+	m.largeFiles = largeFiles
+	m.applyFilter()
+}
+
+// unfilteredEntries returns the complete listing regardless of the filter.
+func (m model) unfilteredEntries() ([]dirEntry, []fileEntry) {
+	if m.filterOn {
+		return m.filterEntries, m.filterLargeFiles
+	}
+	return m.entries, m.largeFiles
+}
+
+// updateFilterKey handles keys while the filter prompt has focus.
+func (m model) updateFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.clearFilter()
+		m.status = "Filter cleared"
+		return m, nil
+	case tea.KeyEnter:
+		m.filterTyping = false
+		if m.filterQuery == "" {
+			m.clearFilter()
+		}
+		return m, nil
+	case tea.KeyTab:
+		m.filterMode = (m.filterMode + 1) % 3
+		m.applyFilter()
+		return m, nil
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterQuery); len(runes) > 0 {
+			m.filterQuery = string(runes[:len(runes)-1])
+			m.applyFilter()
+		}
+		return m, nil
+	case tea.KeyUp, tea.KeyDown:
+		// Let the list move while typing
+		m.filterTyping = false
+		next, cmd := m.updateKey(msg)
+		nm := next.(model)
+		nm.filterTyping = true
+		return nm, cmd
+	case tea.KeySpace:
+		m.filterQuery += " "
+		m.applyFilter()
+		return m, nil
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if unicode.IsPrint(r) {
+				m.filterQuery += string(r)
+			}
+		}
+		m.applyFilter()
+		return m, nil
+	}
+	return m, nil
+}
+
+// filterLine renders the prompt shown under the header while filtering.
+func (m model) filterLine() string {
+	all, large := m.unfilteredEntries()
+	total := len(all)
+	shown := len(m.entries)
+	if m.showLargeFiles {
+		total = len(large)
+		shown = len(m.largeFiles)
+	}
+	cursor := ""
+	if m.filterTyping {
+		cursor = "▏"
+	}
+	return fmt.Sprintf("%s/%s%s%s%s  %s%s  |  %d of %d  |  Tab mode  |  ESC clear%s",
+		colorCyan, colorReset, m.filterQuery, cursor, colorReset,
+		colorGray, m.filterMode, shown, total, colorReset)
+}
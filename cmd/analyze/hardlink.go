@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/fs"
+	"sync"
+	"syscall"
+)
+
+type inodeKey struct {
+	dev uint64
+	ino uint64
+}
+
+// hardlinkSet remembers the inodes with more than one link seen during a scan,
+// so each is counted once no matter how many paths point at it (Nix and pnpm
+// stores, rsnapshot-style backups, ccache).
+type hardlinkSet struct {
+	mu   sync.Mutex
+	seen map[inodeKey]struct{}
+}
+
+func newHardlinkSet() *hardlinkSet {
+	return &hardlinkSet{seen: make(map[inodeKey]struct{})}
+}
+
+// countedSize returns size the first time an inode is seen and 0 for every
+// further link to it. Files with a single link skip the set entirely.
+func (s *hardlinkSet) countedSize(info fs.FileInfo, size int64) int64 {
+	if s == nil || info.IsDir() {
+		return size
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok || stat.Nlink <= 1 {
+		return size
+	}
+
+	key := inodeKey{dev: uint64(stat.Dev), ino: uint64(stat.Ino)}
+	s.mu.Lock()
+	_, dup := s.seen[key]
+	if !dup {
+		s.seen[key] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	if dup {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// helpKey is one line of the key reference. Keys are written the way they
+// are bound: lowercase letters without Shift, uppercase letters with it.
+type helpKey struct {
+	keys   string
+	action string
+}
+
+type helpSection struct {
+	title string
+	keys  []helpKey
+}
+
+// helpSections lists every key of the listing. The footer only has room for
+// the most common ones.
+var helpSections = []helpSection{
+	{"Move", []helpKey{
+		{"↑↓  k j", "Select"},
+		{"PgUp PgDn Home End", "Page"},
+		{"Enter  →  l", "Enter the directory"},
+		{"←  h  b", "Back"},
+		{"g", "Go to a path"},
+		{"B", "Go up from the path in the header"},
+	}},
+	{"View", []helpKey{
+		{"/", "Filter by name"},
+		{"s", "Sort by size, name, age or files"},
+		{"z", "Compact view / show all"},
+		{"i", "Details of the selected entry"},
+		{"t", "Largest files"},
+		{"e", "File types"},
+		{"a", "Stale data"},
+		{"c", "Compare with an earlier scan"},
+		{"H", "Deletion history"},
+		{"W", "Watch for changes"},
+		{"M", "Include network and FUSE mounts"},
+		{"r", "Rescan"},
+	}},
+	{"Act", []helpKey{
+		{"o", "Open"},
+		{"f", "Show in the file manager"},
+		{"y", "Copy the selected path"},
+		{"Y", "Copy the current directory"},
+		{"Space", "Select for a batch delete"},
+		{"⌫", "Move to Trash"},
+		{"D", "Delete permanently"},
+	}},
+}
+
+// renderHelp lists the keys of the listing.
+func (m model) renderHelp(b *strings.Builder) {
+	fmt.Fprintf(b, "%sKeys%s\n\n", colorPurpleBold, colorReset)
+	for _, section := range helpSections {
+		fmt.Fprintf(b, "  %s%s%s\n", colorBold, section.title, colorReset)
+		for _, key := range section.keys {
+			fmt.Fprintf(b, "    %s%s%s  %s\n", colorCyan, padName(key.keys, 20), colorReset, key.action)
+		}
+		fmt.Fprintln(b)
+	}
+	fmt.Fprintf(b, "%s? Close  |  q Quit%s\n", colorGray, colorReset)
+}
+
+// updateHelpKey handles keys while the key reference is shown.
+func (m model) updateHelpKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "?":
+		m.showHelp = false
+	}
+	return m, nil
+}
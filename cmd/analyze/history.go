@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/gob"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	scanHistoryDir     = "history"
+	scanSnapshotExt    = ".snap"
+	maxScanSnapshots   = 30                   // Per scanned path
+	maxScanHistorySize = 512 << 20            // Bytes of snapshots kept across all paths
+	scanSnapshotMaxAge = 180 * 24 * time.Hour // Older snapshots are pruned
+	scanSnapshotMinGap = time.Hour            // A rescan within the hour replaces the newest snapshot
+)
+
+// snapshotHeader is written ahead of the tree, so listing snapshots doesn't
+// need to decode whole trees.
+type snapshotHeader struct {
+	Path      string
+	ScanTime  time.Time
+	Options   scanOptions
+	TotalSize int64
+}
+
+// scanSnapshot is one stored scan, as listed in the history picker.
+type scanSnapshot struct {
+	snapshotHeader
+	file string
+}
+
+type snapshotsLoadedMsg struct {
+	path      string
+	snapshots []scanSnapshot
+	err       error
+}
+
+type snapshotTreeMsg struct {
+	snapshot scanSnapshot
+	tree     *dirNode
+	err      error
+}
+
+// getScanHistoryPath returns the directory holding the snapshots of path.
+func getScanHistoryPath(path string) (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, scanHistoryDir, fmt.Sprintf("%x", xxhash.Sum64String(path))), nil
+}
+
+// saveScanSnapshot stores a fresh scan of path and prunes old snapshots.
+func saveScanSnapshot(path string, opts scanOptions, result scanResult) error {
+	if result.Tree == nil {
+		return fmt.Errorf("scan result has no tree")
+	}
+	scanTime := result.Tree.ScannedAt
+	dir, err := getScanHistoryPath(path)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, strconv.FormatInt(scanTime.UnixNano(), 10)+scanSnapshotExt)
+	tmp, err := os.CreateTemp(dir, "snapshot-*.tmp")
+	if err != nil {
+		return err
+	}
+	encoder := gob.NewEncoder(tmp)
+	header := snapshotHeader{Path: path, ScanTime: scanTime, Options: opts, TotalSize: result.TotalSize}
+	treeSaveMu.RLock()
+	if err = encoder.Encode(header); err == nil {
+		err = encoder.Encode(result.Tree)
+	}
+	treeSaveMu.RUnlock()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	pruneScanSnapshots(dir, scanTime)
+	pruneScanHistory(filepath.Dir(dir), file)
+	return nil
+}
+
+// pruneScanSnapshots applies the retention limits after newest was written:
+// the previous snapshot goes if it is less than scanSnapshotMinGap older, then
+// anything past scanSnapshotMaxAge or maxScanSnapshots.
+func pruneScanSnapshots(dir string, newest time.Time) {
+	times, files := snapshotFiles(dir)
+	var keep int
+	for i := range files {
+		switch {
+		case times[i].Equal(newest):
+			keep++
+			continue
+		case i == 1 && newest.Sub(times[i]) < scanSnapshotMinGap:
+		case time.Since(times[i]) > scanSnapshotMaxAge:
+		case keep >= maxScanSnapshots:
+		default:
+			keep++
+			continue
+		}
+		_ = os.Remove(files[i])
+	}
+}
+
+// pruneScanHistory bounds the snapshots of all paths together: expired ones
+// go, then the oldest until they fit in maxScanHistorySize. keep, the
+// snapshot just written, always stays.
+func pruneScanHistory(historyRoot, keep string) {
+	dirs, err := os.ReadDir(historyRoot)
+	if err != nil {
+		return
+	}
+	type snapshotFile struct {
+		time time.Time
+		path string
+		size int64
+	}
+	var all []snapshotFile
+	var total int64
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		dirPath := filepath.Join(historyRoot, dir.Name())
+		times, files := snapshotFiles(dirPath)
+		kept := 0
+		for i, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				continue
+			}
+			if file != keep && time.Since(times[i]) > scanSnapshotMaxAge {
+				_ = os.Remove(file)
+				continue
+			}
+			all = append(all, snapshotFile{time: times[i], path: file, size: info.Size()})
+			total += info.Size()
+			kept++
+		}
+		if kept == 0 {
+			// Only succeeds when nothing else, e.g. a temp file, is left
+			_ = os.Remove(dirPath)
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].time.Before(all[j].time) })
+	for _, file := range all {
+		if total <= maxScanHistorySize {
+			break
+		}
+		if file.path == keep {
+			continue
+		}
+		if os.Remove(file.path) == nil {
+			total -= file.size
+		}
+	}
+}
+
+// snapshotFiles returns the snapshot files in dir with their scan times,
+// newest first.
+func snapshotFiles(dir string) ([]time.Time, []string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil
+	}
+	type snapshotFile struct {
+		time time.Time
+		path string
+	}
+	var found []snapshotFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, scanSnapshotExt) {
+			continue
+		}
+		nanos, err := strconv.ParseInt(strings.TrimSuffix(name, scanSnapshotExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		found = append(found, snapshotFile{time: time.Unix(0, nanos), path: filepath.Join(dir, name)})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].time.After(found[j].time) })
+
+	times := make([]time.Time, len(found))
+	files := make([]string, len(found))
+	for i, f := range found {
+		times[i], files[i] = f.time, f.path
+	}
+	return times, files
+}
+
+// listScanSnapshots returns the snapshots that cover path taken before
+// before, newest first: those of path itself and those of any parent
+// directory it was scanned under. A zero before lists them all.
+func listScanSnapshots(path string, before time.Time) ([]scanSnapshot, error) {
+	var snapshots []scanSnapshot
+	for dir := path; ; dir = filepath.Dir(dir) {
+		historyPath, err := getScanHistoryPath(dir)
+		if err != nil {
+			return nil, err
+		}
+		_, files := snapshotFiles(historyPath)
+		for _, file := range files {
+			header, err := readSnapshotHeader(file)
+			if err != nil || header.Path != dir {
+				continue
+			}
+			if !before.IsZero() && !header.ScanTime.Before(before) {
+				continue
+			}
+			snapshots = append(snapshots, scanSnapshot{snapshotHeader: header, file: file})
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].ScanTime.After(snapshots[j].ScanTime)
+	})
+	return snapshots, nil
+}
+
+func readSnapshotHeader(file string) (snapshotHeader, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return snapshotHeader{}, err
+	}
+	defer f.Close()
+	var header snapshotHeader
+	err = gob.NewDecoder(f).Decode(&header)
+	return header, err
+}
+
+func loadSnapshotTree(snapshot scanSnapshot) (*dirNode, error) {
+	f, err := os.Open(snapshot.file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	decoder := gob.NewDecoder(f)
+	var header snapshotHeader
+	if err := decoder.Decode(&header); err != nil {
+		return nil, err
+	}
+	var tree dirNode
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return &tree, nil
+}
+
+// loadSnapshotsCmd lists the snapshots to compare path with, leaving out the
+// one taken by the scan on screen (scannedAt) and any newer.
+func loadSnapshotsCmd(path string, scannedAt time.Time) tea.Cmd {
+	return func() tea.Msg {
+		snapshots, err := listScanSnapshots(path, scannedAt)
+		return snapshotsLoadedMsg{path: path, snapshots: snapshots, err: err}
+	}
+}
+
+func loadSnapshotTreeCmd(snapshot scanSnapshot) tea.Cmd {
+	return func() tea.Msg {
+		tree, err := loadSnapshotTree(snapshot)
+		return snapshotTreeMsg{snapshot: snapshot, tree: tree, err: err}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestScanHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := "/data/projects"
+	tree := &dirNode{Name: "projects", Path: root, IsDir: true, Size: 10}
+
+	// An expired snapshot of another path goes with its directory
+	otherDir, err := getScanHistoryPath("/elsewhere")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(otherDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	expired := time.Now().Add(-scanSnapshotMaxAge - time.Hour)
+	if err := os.WriteFile(filepath.Join(otherDir, strconv.FormatInt(expired.UnixNano(), 10)+scanSnapshotExt), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	earlier := time.Now().Add(-48 * time.Hour)
+	latest := time.Now()
+	for _, scanned := range []time.Time{earlier, latest} {
+		tree.ScannedAt = scanned
+		if err := saveScanSnapshot(root, scanOptions{}, scanResult{Tree: tree, TotalSize: tree.Size}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	all, err := listScanSnapshots(root, time.Time{})
+	if err != nil || len(all) != 2 {
+		t.Fatalf("listed %d snapshots (%v), want 2", len(all), err)
+	}
+	// The scan on screen is not offered for comparison with itself
+	older, err := listScanSnapshots(filepath.Join(root, "app"), latest)
+	if err != nil || len(older) != 1 || !older[0].ScanTime.Equal(earlier) {
+		t.Errorf("snapshots before the latest scan: %+v (%v)", older, err)
+	}
+	if _, err := os.Stat(otherDir); !os.IsNotExist(err) {
+		t.Errorf("expired snapshot directory was kept: %v", err)
+	}
+}
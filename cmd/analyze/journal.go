@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	deleteJournalFile = "delete_journal.json"
+	maxJournalRecords = 200
+)
+
+// deletionRecord is one delete issued from the analyzer.
+type deletionRecord struct {
+	Path       string     `json:"path"`
+	Size       int64      `json:"size"`
+	Items      int64      `json:"items"`
+	DeletedAt  time.Time  `json:"deleted_at"`
+	TrashedTo  string     `json:"trashed_to,omitempty"`  // Empty when deleted permanently
+	RestoredAt *time.Time `json:"restored_at,omitempty"` // Nil until restored
+}
+
+func (r deletionRecord) restorable() bool {
+	return r.TrashedTo != "" && r.RestoredAt == nil
+}
+
+type journalLoadedMsg struct {
+	records []deletionRecord
+	err     error
+}
+
+type restoreResultMsg struct {
+	restored []string
+	err      error
+}
+
+var journalMu sync.Mutex
+
+func getDeleteJournalPath() (string, error) {
+	cacheDir, err := getCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, deleteJournalFile), nil
+}
+
+// loadDeleteJournal returns all records, newest first.
+func loadDeleteJournal() ([]deletionRecord, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	return loadDeleteJournalLocked()
+}
+
+func loadDeleteJournalLocked() ([]deletionRecord, error) {
+	journalPath, err := getDeleteJournalPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(journalPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var records []deletionRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		_ = os.Rename(journalPath, journalPath+".corrupt")
+		return nil, nil
+	}
+	return records, nil
+}
+
+func persistDeleteJournalLocked(records []deletionRecord) error {
+	journalPath, err := getDeleteJournalPath()
+	if err != nil {
+		return err
+	}
+	if len(records) > maxJournalRecords {
+		records = records[:maxJournalRecords]
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpPath := journalPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, journalPath)
+}
+
+// appendDeleteJournal records a finished delete at the front of the journal.
+func appendDeleteJournal(record deletionRecord) error {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	records, err := loadDeleteJournalLocked()
+	if err != nil {
+		return err
+	}
+	records = append([]deletionRecord{record}, records...)
+	return persistDeleteJournalLocked(records)
+}
+
+// restoreDeletions moves the given trashed records back to their original
+// location, newest first, and marks them restored in the journal.
+func restoreDeletions(targets []deletionRecord) ([]string, error) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+
+	records, err := loadDeleteJournalLocked()
+	if err != nil {
+		return nil, err
+	}
+
+	var restored []string
+	var firstErr error
+	for _, target := range targets {
+		if !target.restorable() {
+			continue
+		}
+		if err := restoreFromTrash(target); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		restored = append(restored, target.Path)
+		for i := range records {
+			if records[i].TrashedTo == target.TrashedTo && records[i].DeletedAt.Equal(target.DeletedAt) {
+				now := time.Now()
+				records[i].RestoredAt = &now
+				break
+			}
+		}
+	}
+
+	if len(restored) > 0 {
+		if err := persistDeleteJournalLocked(records); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return restored, firstErr
+}
+
+func restoreFromTrash(record deletionRecord) error {
+	if _, err := os.Lstat(record.TrashedTo); err != nil {
+		return fmt.Errorf("%s is no longer in the Trash", filepath.Base(record.Path))
+	}
+	if _, err := os.Lstat(record.Path); err == nil {
+		return fmt.Errorf("%s already exists", displayPath(record.Path))
+	}
+	if err := os.MkdirAll(filepath.Dir(record.Path), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(record.TrashedTo, record.Path); err != nil {
+		return err
+	}
+
+	// Drop the matching .trashinfo so file managers don't list a ghost entry
+	filesDir := filepath.Dir(record.TrashedTo)
+	if filepath.Base(filesDir) == "files" {
+		infoFile := filepath.Join(filepath.Dir(filesDir), "info", filepath.Base(record.TrashedTo)+".trashinfo")
+		_ = os.Remove(infoFile)
+	}
+	return nil
+}
+
+func loadJournalCmd() tea.Cmd {
+	return func() tea.Msg {
+		records, err := loadDeleteJournal()
+		return journalLoadedMsg{records: records, err: err}
+	}
+}
+
+func restoreDeletionsCmd(targets []deletionRecord) tea.Cmd {
+	return func() tea.Msg {
+		restored, err := restoreDeletions(targets)
+		return restoreResultMsg{restored: restored, err: err}
+	}
+}
+
+// formatAge renders how long ago t was, e.g. "3h ago".
+func formatAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+// journalStateLabel describes whether a record can still be restored.
+func journalStateLabel(r deletionRecord) string {
+	switch {
+	case r.RestoredAt != nil:
+		return "restored"
+	case r.TrashedTo == "":
+		return "permanent"
+	default:
+		return "in trash"
+	}
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// largeFileIndex is a file index that can list large file candidates without
+// walking the tree. Candidates may be stale and are verified before display.
+type largeFileIndex interface {
+	// name identifies the backend in the UI.
+	name() string
+	// available reports whether the backend can be queried on this system.
+	available() bool
+	// updatedAt is when the index last caught up with the disk, zero for
+	// indexes that are kept current by the system.
+	updatedAt() time.Time
+	// candidates emits paths below root that may be at least minSize.
+	candidates(ctx context.Context, root string, minSize int64, emit func(string)) error
+}
+
+// largeIndexInfo records which index supplied the large files of a scan.
+type largeIndexInfo struct {
+	Backend   string    // "" when the files were found by the scan alone
+	UpdatedAt time.Time // Zero for live indexes
+}
+
+// largeFileIndexes in order of preference.
+var largeFileIndexes = []largeFileIndex{
+	spotlightIndex{},
+	locateIndex{command: "plocate", database: "/var/lib/plocate/plocate.db"},
+	locateIndex{command: "locate", database: "/var/lib/mlocate/mlocate.db", backend: "mlocate"},
+}
+
+func availableLargeFileIndex() largeFileIndex {
+	for _, index := range largeFileIndexes {
+		if index.available() {
+			return index
+		}
+	}
+	return nil
+}
+
+// spotlightIndex asks macOS Spotlight (mdfind), which can filter by size.
+type spotlightIndex struct{}
+
+func (spotlightIndex) name() string { return "spotlight" }
+
+func (spotlightIndex) available() bool {
+	if runtime.GOOS != "darwin" {
+		return false
+	}
+	_, err := exec.LookPath("mdfind")
+	return err == nil
+}
+
+func (spotlightIndex) updatedAt() time.Time { return time.Time{} }
+
+func (spotlightIndex) candidates(ctx context.Context, root string, minSize int64, emit func(string)) error {
+	ctx, cancel := context.WithTimeout(ctx, mdlsTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("kMDItemFSSize >= %d", minSize)
+	output, err := exec.CommandContext(ctx, "mdfind", "-onlyin", root, query).Output()
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			emit(line)
+		}
+	}
+	return nil
+}
+
+// locateIndex reads the database of plocate or mlocate, which is refreshed by
+// updatedb, usually daily. It has no sizes, so every path below root is a
+// candidate.
+type locateIndex struct {
+	command  string
+	database string
+	backend  string // Name shown in the UI when it differs from command
+}
+
+func (l locateIndex) name() string {
+	if l.backend != "" {
+		return l.backend
+	}
+	return l.command
+}
+
+func (l locateIndex) available() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+	if _, err := exec.LookPath(l.command); err != nil {
+		return false
+	}
+	// The database is usually only readable by the locate group
+	f, err := os.Open(l.database)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return true
+}
+
+func (l locateIndex) updatedAt() time.Time {
+	info, err := os.Stat(l.database)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func (l locateIndex) candidates(ctx context.Context, root string, _ int64, emit func(string)) error {
+	ctx, cancel := context.WithTimeout(ctx, locateTimeout)
+	defer cancel()
+
+	prefix := strings.TrimSuffix(root, "/") + "/"
+	cmd := exec.CommandContext(ctx, l.command, "-0", "-d", l.database, "--", escapeLocatePattern(prefix))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	// locate matches anywhere in the path, so keep only what is below root
+	reader := bufio.NewReaderSize(stdout, 64<<10)
+	for {
+		path, err := reader.ReadString(0)
+		if path = strings.TrimSuffix(path, "\x00"); strings.HasPrefix(path, prefix) {
+			emit(path)
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err := cmd.Wait(); err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// escapeLocatePattern keeps locate from reading a path as a glob.
+func escapeLocatePattern(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// findIndexedLargeFiles queries the preferred index for large files inside
+// the folded directories of a scan of root, which the scan sized without
+// listing their files. Every candidate is verified with Lstat, dropping paths
+// that are gone, too small or left out by the user rules and scan options.
+// The Backend of the info is "" when no index was used.
+func findIndexedLargeFiles(ctx context.Context, root string, minSize int64, opts scanOptions, folded []string) ([]fileEntry, largeIndexInfo) {
+	if len(folded) == 0 {
+		return nil, largeIndexInfo{}
+	}
+	index := availableLargeFileIndex()
+	if index == nil {
+		return nil, largeIndexInfo{}
+	}
+
+	foldedDirs := make(map[string]bool, len(folded))
+	for _, dir := range folded {
+		foldedDirs[dir] = true
+	}
+	skippedMounts := skippedMountsBelow(root, opts)
+
+	var rootDev uint64
+	hasRootDev := false
+	if info, err := os.Lstat(root); err == nil {
+		rootDev, hasRootDev = deviceOf(info)
+	}
+
+	paths := make(chan string, 1024)
+	var mu sync.Mutex
+	var files []fileEntry
+	var wg sync.WaitGroup
+	for range minWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				info, err := os.Lstat(path)
+				if err != nil || !info.Mode().IsRegular() {
+					continue
+				}
+				size := getActualFileSize(path, info)
+				if size < minSize {
+					continue
+				}
+				if opts.OneFileSystem && hasRootDev {
+					if dev, ok := deviceOf(info); ok && dev != rootDev {
+						continue
+					}
+				}
+				if skippedByRules(root, path) {
+					continue
+				}
+				mu.Lock()
+				files = append(files, fileEntry{Name: filepath.Base(path), Path: path, Size: size})
+				if len(files) > largeFileLimit*8 {
+					files = topLargeFiles(files, largeFileLimit)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := index.candidates(ctx, root, minSize, func(path string) {
+		// String checks only: a path on a mount the scan skipped is never
+		// touched, a dead network mount would block Lstat
+		if shouldSkipFileForLargeTracking(path) || !inFoldedDir(path, root, foldedDirs) {
+			return
+		}
+		for _, mount := range skippedMounts {
+			if isSameOrBelow(path, mount) {
+				return
+			}
+		}
+		paths <- path
+	})
+	close(paths)
+	wg.Wait()
+	if err != nil {
+		return nil, largeIndexInfo{}
+	}
+	return topLargeFiles(files, largeFileLimit), largeIndexInfo{Backend: index.name(), UpdatedAt: index.updatedAt()}
+}
+
+// inFoldedDir reports whether one of the directories between root and path
+// was folded by the scan.
+func inFoldedDir(path, root string, folded map[string]bool) bool {
+	for dir := filepath.Dir(path); dir != root && isSameOrBelow(dir, root); dir = filepath.Dir(dir) {
+		if folded[dir] {
+			return true
+		}
+	}
+	return false
+}
+
+// skippedMountsBelow lists the mount points below root that a scan with opts
+// does not descend into.
+func skippedMountsBelow(root string, opts scanOptions) []string {
+	var skipped []string
+	for _, mount := range readMounts() {
+		if mount.Point != root && isSameOrBelow(mount.Point, root) && opts.mountSkipReason(mount.FSType) != "" {
+			skipped = append(skipped, mount.Point)
+		}
+	}
+	return skipped
+}
+
+// skippedByRules reports whether a skip rule covers path or any directory
+// between root and path.
+func skippedByRules(root, path string) bool {
+	isDir := false
+	for p := path; p != root && isSameOrBelow(p, root); p = filepath.Dir(p) {
+		if evalRules(rulesForDir(filepath.Dir(p)), p, isDir) == ruleSkip {
+			return true
+		}
+		isDir = true
+	}
+	return false
+}
+
+// mergeLargeFiles combines the large files found by the scan with those from
+// an index, keeping each path once.
+func mergeLargeFiles(scanned, indexed []fileEntry) []fileEntry {
+	seen := make(map[string]bool, len(scanned))
+	merged := make([]fileEntry, 0, len(scanned)+len(indexed))
+	for _, file := range scanned {
+		seen[file.Path] = true
+		merged = append(merged, file)
+	}
+	for _, file := range indexed {
+		if !seen[file.Path] {
+			merged = append(merged, file)
+		}
+	}
+	return topLargeFiles(merged, largeFileLimit)
+}
+
+// label describes the source of the large files, e.g. "plocate, updated 3h ago".
+func (i largeIndexInfo) label() string {
+	switch {
+	case i.Backend == "":
+		return "found by scan"
+	case i.UpdatedAt.IsZero():
+		return i.Backend
+	default:
+		return fmt.Sprintf("%s, updated %s", i.Backend, formatAge(i.UpdatedAt))
+	}
+}
+
+// stale reports whether the index is old enough to miss recent files.
+func (i largeIndexInfo) stale() bool {
+	return !i.UpdatedAt.IsZero() && time.Since(i.UpdatedAt) > largeIndexStaleAge
+}
@@ -0,0 +1,87 @@
+package main
+
+import "fmt"
+
+var (
+	// compactEntries is how many entries the compact view lists before it
+	// sums the rest into an "other items" row (the -compact-entries flag).
+	compactEntries = defaultCompactEntries
+	// largeFileLimit is how many large files are kept per directory (the
+	// -max-large-files flag).
+	largeFileLimit = defaultLargeFiles
+)
+
+// shownEntries is the number of entries that can be selected: all of them,
+// or the first compactEntries in the compact view.
+func (m model) shownEntries() int {
+	if m.compact && !m.inOverviewMode() && len(m.entries) > compactEntries {
+		return compactEntries
+	}
+	return len(m.entries)
+}
+
+// otherEntries sums the entries the compact view leaves out.
+func (m model) otherEntries() (int, int64) {
+	shown := m.shownEntries()
+	var size int64
+	for _, entry := range m.entries[shown:] {
+		if entry.Size > 0 {
+			size += entry.Size
+		}
+	}
+	return len(m.entries) - shown, size
+}
+
+// listRows is the number of rows of the listing, including the "other
+// items" row.
+func (m model) listRows() int {
+	if count, _ := m.otherEntries(); count > 0 {
+		return m.shownEntries() + 1
+	}
+	return len(m.entries)
+}
+
+// toggleCompact switches between listing every entry and the compact view.
+func (m *model) toggleCompact() {
+	m.compact = !m.compact
+	m.clampEntrySelection()
+	if m.compact {
+		m.status = fmt.Sprintf("Compact view: the first %d entries", compactEntries)
+	} else {
+		m.status = fmt.Sprintf("Showing all %s entries", formatNumber(int64(len(m.entries))))
+	}
+}
+
+// pageList moves the selection by a page, or to the first or last row.
+func (m *model) pageList(key string) {
+	if m.showLargeFiles {
+		m.largeSelected = pageIndex(key, m.largeSelected, len(m.largeFiles), calculateViewport(m.height, true))
+		m.clampLargeSelection()
+		return
+	}
+	m.selected = pageIndex(key, m.selected, m.shownEntries(), calculateViewport(m.height, false))
+	m.clampEntrySelection()
+}
+
+func pageIndex(key string, index, count, viewport int) int {
+	switch key {
+	case "pgup":
+		index -= viewport
+	case "pgdown":
+		index += viewport
+	case "home":
+		index = 0
+	case "end":
+		index = count - 1
+	}
+	return max(min(index, count-1), 0)
+}
+
+// listPosition describes the rows on screen of a long listing, e.g.
+// "31–60 of 412", and is empty when everything fits.
+func listPosition(offset, rows, viewport int) string {
+	if rows <= viewport {
+		return ""
+	}
+	return fmt.Sprintf("%d–%d of %s", offset+1, min(offset+viewport, rows), formatNumber(int64(rows)))
+}
@@ -4,14 +4,17 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -22,7 +25,13 @@ type dirEntry struct {
 	Path       string
 	Size       int64
 	IsDir      bool
+	IsSymlink  bool // Listed, never followed; Name ends in " →"
 	LastAccess time.Time
+	ModTime    time.Time
+	FileCount  int64  // Files below a directory entry (1 for a file)
+	IsMount    bool   // Root of another filesystem
+	MountFS    string // Filesystem type of a mount point
+	MountSkip  string // Why a mount point was not scanned
 }
 
 type fileEntry struct {
@@ -35,14 +44,16 @@ type scanResult struct {
 	Entries    []dirEntry
 	LargeFiles []fileEntry
 	TotalSize  int64
+	Tree       *dirNode
+	ReadErrors int64    // Directories that could not be listed, set by a full scan
+	Unreadable []string // The first of them
 }
 
 type cacheEntry struct {
-	Entries    []dirEntry
-	LargeFiles []fileEntry
-	TotalSize  int64
-	ModTime    time.Time
-	ScanTime   time.Time
+	Tree     *dirNode
+	Options  scanOptions
+	ModTime  time.Time
+	ScanTime time.Time
 }
 
 type historyEntry struct {
@@ -50,15 +61,18 @@ type historyEntry struct {
 	Entries       []dirEntry
 	LargeFiles    []fileEntry
 	TotalSize     int64
+	Tree          *dirNode
 	Selected      int
 	EntryOffset   int
 	LargeSelected int
 	LargeOffset   int
 	Dirty         bool
 	IsOverview    bool
+	SortOrder     sortOrder
 }
 
 type scanResultMsg struct {
+	path   string
 	result scanResult
 	err    error
 }
@@ -73,17 +87,19 @@ type overviewSizeMsg struct {
 type tickMsg time.Time
 
 type deleteProgressMsg struct {
-	done  bool
-	err   error
-	count int64
-	path  string
+	done    bool
+	err     error // First failure, if any
+	count   int64
+	results []deleteItemResult
 }
 
 type model struct {
+	scanRoot             string // Scans of this directory are kept as snapshots, those below it are drill-downs
 	path                 string
 	history              []historyEntry
 	entries              []dirEntry
 	largeFiles           []fileEntry
+	tree                 *dirNode // Tree of the most recent scan; m.path is somewhere inside it
 	selected             int
 	offset               int
 	status               string
@@ -97,7 +113,25 @@ type model struct {
 	showLargeFiles       bool
 	isOverview           bool
 	deleteConfirm        bool
-	deleteTarget         *dirEntry
+	deleteTargets        []dirEntry
+	deletePreview        *deletePreview // Dry run of the pending delete, nil until P is pressed
+	previewLoading       bool
+	deletePermanent      bool                // Pending or running delete bypasses the trash
+	deleteFailures       []deleteItemResult  // Failures of the last delete, shown until the next one
+	marked               map[string]dirEntry // Entries marked with Space for a batch delete
+	filterOn             bool                // A filter is applied to entries and largeFiles
+	filterTyping         bool                // The filter prompt has keyboard focus
+	filterQuery          string
+	filterMode           filterMode
+	sortOrder            sortOrder
+	scanOpts             scanOptions
+	ctx                  context.Context    // Cancelled when the analyzer exits
+	scanCtx              context.Context    // Context of the scan in progress
+	scanCancel           context.CancelFunc // Cancels the scan in progress
+	overviewCtx          context.Context    // Context of overview sizing, nil until it starts
+	overviewCancel       context.CancelFunc // Stops overview sizing when a directory is entered
+	filterEntries        []dirEntry         // Unfiltered entries while filterOn
+	filterLargeFiles     []fileEntry        // Unfiltered large files while filterOn
 	deleting             bool
 	deleteCount          *int64
 	cache                map[string]historyEntry
@@ -110,8 +144,62 @@ type model struct {
 	overviewCurrentPath  *string
 	overviewScanning     bool
 	overviewScanningSet  map[string]bool // Track which paths are currently being scanned
-	width                int             // Terminal width
-	height               int             // Terminal height
+	showJournal          bool
+	journal              []deletionRecord // Newest first
+	journalSelected      int
+	journalOffset        int
+	showSnapshots        bool           // Picking an earlier scan to compare with
+	snapshots            []scanSnapshot // Newest first
+	snapshotSelected     int
+	snapshotOffset       int
+	showDiff             bool         // Comparing with diffSnapshot
+	diffSnapshot         scanSnapshot // The earlier scan
+	diffBase             *dirNode     // Tree of the earlier scan
+	diffPath             string       // Directory whose changes are listed
+	diffStack            []string     // Directories above diffPath entered in the diff
+	diffRows             []diffRow
+	diffOld              int64 // Size of diffPath in the earlier scan
+	diffNew              int64 // Size of diffPath now
+	diffSelected         int
+	diffOffset           int
+	showTypes            bool           // File type breakdown of m.path
+	typeRows             []typeCategory // Largest first
+	typeSelected         int
+	typeOffset           int
+	typeCategory         string             // Category whose largest files are listed, "" for the breakdown
+	typeFiles            []fileEntry        // Largest files of typeCategory
+	typeFilesLoading     bool               // Still walking m.path for typeFiles
+	typeFilesCancel      context.CancelFunc // Stops the walk
+	typeFileSelected     int
+	typeFileOffset       int
+	showStale            bool       // Age breakdown and stale candidates of m.path
+	staleByModified      bool       // Ages go by modification instead of last use
+	staleBucket          int        // Candidates are at least this old (index into ageBucketLimits + 1)
+	staleAtime           string     // atimeMode of m.path
+	staleRows            []dirEntry // Largest first
+	staleSelected        int
+	staleOffset          int
+	watch                *dirWatcher      // Watch mode, nil when off
+	watchPath            string           // m.path the watches were set up for
+	watchTree            *dirNode         // m.tree the watches were set up for
+	watchCount           int              // Directories watched
+	watchLimited         bool             // The watch budget ran out before the subtree did
+	watchOrig            map[string]int64 // Size at scan time of entries changed since
+	showDetail           bool             // Details pane next to the listing
+	detail               entryDetail      // Details of detailPath, once loaded
+	detailPath           string           // Entry the details pane was last asked for
+	compact              bool             // List the first compactEntries and sum up the rest
+	pendingSelect        string           // Entry to select once the listing being loaded has it
+	gotoTyping           bool             // The go to path prompt has keyboard focus
+	gotoInput            string           // Text typed at the prompt
+	gotoHint             string           // Completions offered for gotoInput
+	crumbFocus           bool             // The path in the header has keyboard focus
+	crumbSelected        int              // Index into breadcrumbs()
+	lastClickPath        string           // Row clicked last, for double clicks
+	lastClickAt          time.Time        // When lastClickPath was clicked
+	showHelp             bool             // Key reference shown instead of the listing
+	width                int              // Terminal width
+	height               int              // Terminal height
 }
 
 func (m model) inOverviewMode() bool {
@@ -119,9 +207,36 @@ func (m model) inOverviewMode() bool {
 }
 
 func main() {
+	jsonOutput := flag.Bool("json", false, "scan the path without the TUI and print a JSON report")
+	csvOutput := flag.Bool("csv", false, "scan the path without the TUI and print a CSV report")
+	oneFileSystem := flag.Bool("x", false, "stay on the filesystem of the scanned path (like du -x)")
+	includeRemote := flag.Bool("remote", false, "descend into network and FUSE mounts")
+	watch := flag.Bool("watch", false, "keep the view current as files change (Linux, inotify)")
+	flag.BoolVar(&duCheck, "du-check", false, "also size directories with du -sk and report differences on exit")
+	compact := flag.Bool("compact", false, "list only the first entries in the current sort order and sum up the rest in one row")
+	flag.IntVar(&compactEntries, "compact-entries", defaultCompactEntries, "entries listed by the compact view")
+	flag.IntVar(&largeFileLimit, "max-large-files", defaultLargeFiles, "large files kept per directory")
+	flag.Parse()
+	if compactEntries < 1 || largeFileLimit < 1 {
+		fmt.Fprintln(os.Stderr, "-compact-entries and -max-large-files must be at least 1")
+		os.Exit(exitUsage)
+	}
+	opts := scanOptions{OneFileSystem: *oneFileSystem, IncludeRemote: *includeRemote}
+
 	target := os.Getenv("MO_ANALYZE_PATH")
-	if target == "" && len(os.Args) > 1 {
-		target = os.Args[1]
+	if target == "" && flag.NArg() > 0 {
+		target = flag.Arg(0)
+	}
+
+	format := exportNone
+	switch {
+	case *jsonOutput && *csvOutput:
+		fmt.Fprintln(os.Stderr, "--json and --csv are mutually exclusive")
+		os.Exit(exitUsage)
+	case *jsonOutput:
+		format = exportJSON
+	case *csvOutput:
+		format = exportCSV
 	}
 
 	var abs string
@@ -141,20 +256,47 @@ func main() {
 		isOverview = false
 	}
 
+	if format != exportNone {
+		if isOverview {
+			fmt.Fprintln(os.Stderr, "a path is required with --json or --csv")
+			os.Exit(exitUsage)
+		}
+		// Ctrl+C stops the scan and kills du instead of leaving it behind
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		code := runHeadless(ctx, abs, format, opts, os.Stdout, os.Stderr)
+		stop()
+		waitForDuExit(2 * time.Second)
+		reportDuMismatches(os.Stderr)
+		os.Exit(code)
+	}
+
 	// Prefetch overview cache in background (non-blocking)
 	// Use context with timeout to prevent hanging
-	prefetchCtx, prefetchCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	appCtx, appCancel := context.WithCancel(context.Background())
+	prefetchCtx, prefetchCancel := context.WithTimeout(appCtx, 30*time.Second)
 	defer prefetchCancel()
 	go prefetchOverviewCache(prefetchCtx)
 
-	p := tea.NewProgram(newModel(abs, isOverview), tea.WithAltScreen())
-	if err := p.Start(); err != nil {
+	m := newModel(appCtx, abs, isOverview, opts)
+	m.compact = *compact
+	if *watch {
+		m.toggleWatch()
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	err := p.Start()
+
+	// Stop outstanding scans and make sure no du process outlives us
+	appCancel()
+	waitForDuExit(2 * time.Second)
+	reportDuMismatches(os.Stderr)
+
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "analyzer error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func newModel(path string, isOverview bool) model {
+func newModel(ctx context.Context, path string, isOverview bool, opts scanOptions) model {
 	var filesScanned, dirsScanned, bytesScanned int64
 	currentPath := ""
 	var overviewFilesScanned, overviewDirsScanned, overviewBytesScanned int64
@@ -162,6 +304,7 @@ func newModel(path string, isOverview bool) model {
 
 	m := model{
 		path:                 path,
+		scanRoot:             path,
 		selected:             0,
 		status:               "Preparing scan...",
 		scanning:             !isOverview,
@@ -171,6 +314,9 @@ func newModel(path string, isOverview bool) model {
 		currentPath:          &currentPath,
 		showLargeFiles:       false,
 		isOverview:           isOverview,
+		scanOpts:             opts,
+		ctx:                  ctx,
+		scanCtx:              ctx,
 		cache:                make(map[string]historyEntry),
 		overviewFilesScanned: &overviewFilesScanned,
 		overviewDirsScanned:  &overviewDirsScanned,
@@ -180,6 +326,10 @@ func newModel(path string, isOverview bool) model {
 		overviewScanningSet:  make(map[string]bool),
 	}
 
+	if !isOverview {
+		m.scanCtx, m.scanCancel = context.WithCancel(ctx)
+	}
+
 	// In overview mode, create shortcut entries
 	if isOverview {
 		m.scanning = false
@@ -331,7 +481,7 @@ func (m *model) scheduleOverviewScans() tea.Cmd {
 	for _, idx := range pendingIndices {
 		entry := m.entries[idx]
 		m.overviewScanningSet[entry.Path] = true
-		cmd := scanOverviewPathCmd(entry.Path, idx)
+		cmd := scanOverviewPathCmd(m.overviewContext(), entry.Path, idx, m.overviewProgress())
 		cmds = append(cmds, cmd)
 	}
 
@@ -368,46 +518,103 @@ func (m *model) getScanProgress() (files, dirs, bytes int64) {
 	return
 }
 
+// overviewContext returns the context overview sizing runs in, starting a
+// new one after cancelOverviewScans.
+func (m *model) overviewContext() context.Context {
+	if m.overviewCtx == nil {
+		m.overviewCtx, m.overviewCancel = context.WithCancel(m.ctx)
+	}
+	return m.overviewCtx
+}
+
+// cancelOverviewScans stops sizing the overview locations, which only the
+// overview shows.
+func (m *model) cancelOverviewScans() {
+	if m.overviewCancel != nil {
+		m.overviewCancel()
+		m.overviewCtx, m.overviewCancel = nil, nil
+	}
+}
+
+// overviewProgress is where overview sizing reports its progress.
+func (m *model) overviewProgress() sizeProgress {
+	return sizeProgress{
+		files:   m.overviewFilesScanned,
+		dirs:    m.overviewDirsScanned,
+		bytes:   m.overviewBytesScanned,
+		current: m.overviewCurrentPath,
+	}
+}
+
 func (m model) Init() tea.Cmd {
+	var watchCmd tea.Cmd
+	if m.watch != nil {
+		watchCmd = waitForWatchCmd(m.watch)
+	}
 	if m.inOverviewMode() {
-		return m.scheduleOverviewScans()
+		return tea.Batch(m.scheduleOverviewScans(), watchCmd)
+	}
+	return tea.Batch(m.scanCmd(m.scanCtx, m.path), tickCmd(), watchCmd)
+}
+
+// startScan cancels the scan in progress, if any, and starts scanning path.
+func (m *model) startScan(path string) tea.Cmd {
+	m.cancelScan()
+	m.scanCtx, m.scanCancel = context.WithCancel(m.ctx)
+	return m.scanCmd(m.scanCtx, path)
+}
+
+// cancelScan stops the workers and du processes of the scan in progress.
+func (m *model) cancelScan() {
+	if m.scanCancel != nil {
+		m.scanCancel()
+		m.scanCancel = nil
 	}
-	return tea.Batch(m.scanCmd(m.path), tickCmd())
 }
 
-func (m model) scanCmd(path string) tea.Cmd {
+func (m model) scanCmd(ctx context.Context, path string) tea.Cmd {
 	return func() tea.Msg {
 		// Try to load from persistent cache first
-		if cached, err := loadCacheFromDisk(path); err == nil {
-			result := scanResult{
-				Entries:    cached.Entries,
-				LargeFiles: cached.LargeFiles,
-				TotalSize:  cached.TotalSize,
-			}
-			return scanResultMsg{result: result, err: nil}
+		if cached, err := loadCacheFromDisk(path, m.scanOpts); err == nil {
+			return scanResultMsg{path: path, result: cached.Tree.result(), err: nil}
 		}
 
 		// Use singleflight to avoid duplicate scans of the same path
 		// If multiple goroutines request the same path, only one scan will be performed
-		v, err, _ := scanGroup.Do(path, func() (interface{}, error) {
-			return scanPathConcurrent(path, m.filesScanned, m.dirsScanned, m.bytesScanned, m.currentPath)
-		})
+		key := fmt.Sprintf("%s|%+v", path, m.scanOpts)
+		var v interface{}
+		var err error
+		for {
+			v, err, _ = scanGroup.Do(key, func() (interface{}, error) {
+				return scanPathConcurrent(ctx, path, m.scanOpts, m.filesScanned, m.dirsScanned, m.bytesScanned, m.currentPath)
+			})
+			// We may have joined a scan that its owner cancelled; run our own
+			if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+				continue
+			}
+			break
+		}
 
 		if err != nil {
-			return scanResultMsg{err: err}
+			return scanResultMsg{path: path, err: err}
 		}
 
 		result := v.(scanResult)
 
 		// Save to persistent cache asynchronously with error logging
-		go func(p string, r scanResult) {
-			if err := saveCacheToDisk(p, r); err != nil {
+		go func(p string, opts scanOptions, r scanResult) {
+			if err := saveCacheToDisk(p, opts, r); err != nil {
 				// Log error but don't fail the scan
 				_ = err // Cache save failure is not critical
 			}
-		}(path, result)
+			// Keep a dated copy for comparing with later scans (c). Scans of
+			// directories below it are drill-downs and would only fill the history
+			if p == m.scanRoot {
+				_ = saveScanSnapshot(p, opts, r)
+			}
+		}(path, m.scanOpts, result)
 
-		return scanResultMsg{result: result, err: nil}
+		return scanResultMsg{path: path, result: result, err: nil}
 	}
 }
 
@@ -418,9 +625,28 @@ func tickCmd() tea.Cmd {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	updated, ok := next.(model)
+	if !ok {
+		return next, cmd
+	}
+	// Watches follow navigation and new scans
+	if updated.watch != nil {
+		updated.syncWatch()
+	}
+	// The details pane follows the cursor
+	if detailCmd := updated.syncDetail(); detailCmd != nil {
+		cmd = tea.Batch(cmd, detailCmd)
+	}
+	return updated, cmd
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.updateKey(msg)
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -428,47 +654,147 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case deleteProgressMsg:
 		if msg.done {
 			m.deleting = false
-			if msg.err != nil {
-				m.status = fmt.Sprintf("Failed to delete: %v", msg.err)
-			} else {
-				if msg.path != "" {
-					m.removePathFromView(msg.path)
-					invalidateCache(msg.path)
-				}
-				invalidateCache(m.path)
-				m.status = fmt.Sprintf("Deleted %d items", msg.count)
-				// Mark all caches as dirty
-				for i := range m.history {
-					m.history[i].Dirty = true
-				}
-				for path := range m.cache {
-					entry := m.cache[path]
-					entry.Dirty = true
-					m.cache[path] = entry
+			m.deleteFailures = nil
+			var succeeded []deleteItemResult
+			for _, result := range msg.results {
+				if result.err != nil {
+					m.deleteFailures = append(m.deleteFailures, result)
+					continue
 				}
-				// Refresh the view
-				m.scanning = true
-				// Reset scan counters for rescan
-				atomic.StoreInt64(m.filesScanned, 0)
-				atomic.StoreInt64(m.dirsScanned, 0)
-				atomic.StoreInt64(m.bytesScanned, 0)
-				if m.currentPath != nil {
-					*m.currentPath = ""
-				}
-				return m, tea.Batch(m.scanCmd(m.path), tickCmd())
+				succeeded = append(succeeded, result)
+				delete(m.marked, result.path)
+				m.removePathFromView(result.path)
+				invalidateCache(result.path)
+			}
+			m.status = summarizeDelete(succeeded, m.deleteFailures, m.deletePermanent, msg.count)
+			if len(succeeded) == 0 {
+				return m, nil
 			}
+			invalidateCache(m.path)
+			return m, m.rescanAfterChange()
+		}
+		return m, nil
+	case deletePreviewMsg:
+		m.previewLoading = false
+		if m.deleteConfirm && sameTargets(msg.targets, m.deleteTargets) {
+			preview := msg.preview
+			m.deletePreview = &preview
+		}
+		return m, nil
+	case journalLoadedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Cannot read deletion history: %v", msg.err)
+			return m, nil
+		}
+		m.journal = msg.records
+		m.clampJournalSelection()
+		return m, nil
+	case watchEventMsg:
+		return m, m.handleWatchEvent(msg)
+	case watchRefreshMsg:
+		m.applyRefreshes(msg.refreshes)
+		return m, nil
+	case snapshotsLoadedMsg:
+		if msg.path != m.path || !m.showSnapshots {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Cannot read scan history: %v", msg.err)
+			return m, nil
+		}
+		m.snapshots = msg.snapshots
+		m.status = ""
+		return m, nil
+	case snapshotTreeMsg:
+		if !m.showSnapshots {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Cannot load scan from %s: %v", msg.snapshot.ScanTime.Format("Jan 2 15:04"), msg.err)
+			return m, nil
+		}
+		m.diffSnapshot = msg.snapshot
+		m.diffBase = msg.tree
+		m.diffStack = nil
+		if m.openDiff(m.path) {
+			m.showSnapshots = false
+			m.showDiff = true
+		}
+		return m, nil
+	case copiedMsg:
+		m.handleCopied(msg)
+		return m, nil
+	case detailMsg:
+		if msg.detail.Path == m.detailPath {
+			m.detail = msg.detail
+		}
+		return m, nil
+	case openResultMsg:
+		switch {
+		case msg.err != nil && msg.reveal:
+			m.status = fmt.Sprintf("Cannot show %s: %v", msg.name, msg.err)
+		case msg.err != nil:
+			m.status = fmt.Sprintf("Cannot open %s: %v", msg.name, msg.err)
+		case msg.reveal:
+			m.status = fmt.Sprintf("Showed %s in %s", msg.name, defaultOpener().manager())
+		default:
+			m.status = fmt.Sprintf("Opened %s", msg.name)
+		}
+		return m, nil
+	case typeFilesMsg:
+		if !m.showTypes || msg.path != m.path || msg.category != m.typeCategory {
+			return m, nil
+		}
+		m.typeFilesLoading = false
+		m.typeFilesCancel = nil
+		m.typeFiles = msg.files
+		m.status = ""
+		if msg.err != nil && msg.err != context.Canceled {
+			m.status = fmt.Sprintf("Cannot search %s: %v", displayPath(msg.path), msg.err)
 		}
 		return m, nil
+	case restoreResultMsg:
+		for _, path := range msg.restored {
+			invalidateCache(path)
+			invalidateCache(filepath.Dir(path))
+		}
+		invalidateCache(m.path)
+		switch {
+		case msg.err != nil && len(msg.restored) > 0:
+			m.status = fmt.Sprintf("Restored %d items, then failed: %v", len(msg.restored), msg.err)
+		case msg.err != nil:
+			m.status = fmt.Sprintf("Restore failed: %v", msg.err)
+		case len(msg.restored) == 1:
+			m.status = fmt.Sprintf("Restored %s", displayPath(msg.restored[0]))
+		default:
+			m.status = fmt.Sprintf("Restored %d items", len(msg.restored))
+		}
+		if len(msg.restored) == 0 {
+			return m, loadJournalCmd()
+		}
+		return m, tea.Batch(loadJournalCmd(), m.rescanAfterChange())
 	case scanResultMsg:
+		// Results of a scan we navigated away from, or cancelled, are stale
+		if msg.path != m.path || errors.Is(msg.err, context.Canceled) {
+			return m, nil
+		}
+		m.cancelScan()
 		m.scanning = false
 		if msg.err != nil {
 			m.status = fmt.Sprintf("Scan failed: %v", msg.err)
 			return m, nil
 		}
-		m.entries = msg.result.Entries
-		m.largeFiles = msg.result.LargeFiles
+		if m.filterOn {
+			m.setFilteredListing(msg.result.Entries, msg.result.LargeFiles)
+		} else {
+			m.entries = msg.result.Entries
+			m.largeFiles = msg.result.LargeFiles
+		}
 		m.totalSize = msg.result.TotalSize
+		m.tree = msg.result.Tree
+		m.applySort()
 		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+		m.selectPending()
 		m.clampEntrySelection()
 		m.clampLargeSelection()
 		m.cache[m.path] = cacheSnapshot(m)
@@ -486,6 +812,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Remove from scanning set
 		delete(m.overviewScanningSet, msg.Path)
 
+		if errors.Is(msg.Err, context.Canceled) {
+			// Stopped when a directory was entered; still pending if the overview is back
+			if m.inOverviewMode() {
+				return m, m.scheduleOverviewScans()
+			}
+			return m, nil
+		}
 		if msg.Err == nil {
 			if m.overviewSizeCache == nil {
 				m.overviewSizeCache = make(map[string]int64)
@@ -548,28 +881,48 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Handle delete confirmation
 	if m.deleteConfirm {
-		switch msg.String() {
-		case "delete", "backspace":
+		key := msg.String()
+		// Trash is confirmed by pressing ⌫ again; permanent delete needs an explicit Y
+		confirmed := (!m.deletePermanent && (key == "delete" || key == "backspace")) ||
+			(m.deletePermanent && (key == "y" || key == "Y"))
+		switch {
+		case confirmed:
 			// Confirm delete - start async deletion
-			if m.deleteTarget != nil {
+			if len(m.deleteTargets) > 0 {
 				m.deleteConfirm = false
 				m.deleting = true
 				var deleteCount int64
 				m.deleteCount = &deleteCount
-				targetPath := m.deleteTarget.Path
-				targetName := m.deleteTarget.Name
-				m.deleteTarget = nil
-				m.status = fmt.Sprintf("Deleting %s...", targetName)
-				return m, tea.Batch(deletePathCmd(targetPath, m.deleteCount), tickCmd())
+				targets := m.deleteTargets
+				m.deleteTargets = nil
+				m.deletePreview = nil
+				label := targets[0].Name
+				if len(targets) > 1 {
+					label = fmt.Sprintf("%d items", len(targets))
+				}
+				if m.deletePermanent {
+					m.status = fmt.Sprintf("Deleting %s...", label)
+				} else {
+					m.status = fmt.Sprintf("Moving %s to Trash...", label)
+				}
+				return m, tea.Batch(deleteTargetsCmd(targets, m.deleteCount, m.deletePermanent), tickCmd())
 			}
 			m.deleteConfirm = false
-			m.deleteTarget = nil
+			m.deleteTargets = nil
 			return m, nil
-		case "esc", "q":
+		case key == "p" || key == "P":
+			// Dry run: walk the targets and report what would get in the way
+			if m.deletePreview == nil && !m.previewLoading {
+				m.previewLoading = true
+				return m, previewDeleteCmd(m.deleteTargets)
+			}
+			return m, nil
+		case key == "esc" || key == "q":
 			// Cancel delete with ESC or Q
 			m.status = "Cancelled"
 			m.deleteConfirm = false
-			m.deleteTarget = nil
+			m.deleteTargets = nil
+			m.deletePreview = nil
 			return m, nil
 		default:
 			// Ignore other keys - keep showing confirmation
@@ -577,15 +930,125 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	if m.showHelp {
+		return m.updateHelpKey(msg)
+	}
+	if m.showJournal {
+		return m.updateJournalKey(msg)
+	}
+	if m.showSnapshots {
+		return m.updateSnapshotKey(msg)
+	}
+	if m.showDiff {
+		return m.updateDiffKey(msg)
+	}
+	if m.showTypes {
+		return m.updateTypesKey(msg)
+	}
+	if m.showStale {
+		return m.updateStaleKey(msg)
+	}
+	if m.gotoTyping {
+		return m.updateGotoKey(msg)
+	}
+	if m.crumbFocus {
+		return m.updateCrumbKey(msg)
+	}
+	if m.filterTyping {
+		return m.updateFilterKey(msg)
+	}
+
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
+	case "?":
+		m.showHelp = true
+		return m, nil
+	case "H":
+		m.showJournal = true
+		m.journalSelected = 0
+		m.journalOffset = 0
+		return m, loadJournalCmd()
+	case "/":
+		if !m.inOverviewMode() {
+			m.startFilter()
+		}
+		return m, nil
+	case "g":
+		// Type a path to jump to
+		m.startGoto()
+		return m, nil
+	case "B":
+		// Pick a directory above from the path in the header
+		m.focusBreadcrumbs()
+		return m, nil
+	case "c":
+		// Compare with an earlier scan
+		if m.inOverviewMode() || m.scanning || m.tree == nil {
+			return m, nil
+		}
+		m.showSnapshots = true
+		m.snapshots = nil
+		m.snapshotSelected = 0
+		m.snapshotOffset = 0
+		m.status = "Loading scan history..."
+		return m, loadSnapshotsCmd(m.path, m.tree.ScannedAt)
+	case "a":
+		// Break the directory down by age and list what has gone unused
+		if m.inOverviewMode() || m.scanning || m.tree == nil {
+			return m, nil
+		}
+		m.openStale()
+		return m, nil
+	case "e":
+		// Break the directory down by file type
+		if m.inOverviewMode() || m.scanning || m.tree == nil {
+			return m, nil
+		}
+		m.openTypes()
+		return m, nil
+	case "s":
+		// Cycle the sort order of the listing
+		if !m.inOverviewMode() {
+			m.sortOrder = (m.sortOrder + 1) % sortOrderCount
+			m.applySort()
+			m.status = fmt.Sprintf("Sorted by %s", m.sortOrder)
+		}
+		return m, nil
 	case "esc":
+		if m.filterOn {
+			m.clearFilter()
+			m.status = "Filter cleared"
+			return m, nil
+		}
+		if len(m.marked) > 0 {
+			m.marked = nil
+			m.status = "Selection cleared"
+			return m, nil
+		}
 		if m.showLargeFiles {
 			m.showLargeFiles = false
 			return m, nil
 		}
 		return m, tea.Quit
+	case " ":
+		// Mark the selected entry for a batch delete
+		if m.showLargeFiles {
+			if len(m.largeFiles) > 0 {
+				selected := m.largeFiles[m.largeSelected]
+				m.toggleMark(dirEntry{Name: selected.Name, Path: selected.Path, Size: selected.Size})
+				if m.largeSelected < len(m.largeFiles)-1 {
+					m.largeSelected++
+				}
+				m.clampLargeSelection()
+			}
+		} else if len(m.entries) > 0 && !m.inOverviewMode() {
+			m.toggleMark(m.entries[m.selected])
+			if m.selected < len(m.entries)-1 {
+				m.selected++
+			}
+			m.clampEntrySelection()
+		}
 	case "up", "k":
 		if m.showLargeFiles {
 			if m.largeSelected > 0 {
@@ -609,12 +1072,21 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.largeOffset = m.largeSelected - viewport + 1
 				}
 			}
-		} else if len(m.entries) > 0 && m.selected < len(m.entries)-1 {
+		} else if m.selected < m.shownEntries()-1 {
 			m.selected++
-			viewport := calculateViewport(m.height, false)
-			if m.selected >= m.offset+viewport {
-				m.offset = m.selected - viewport + 1
-			}
+			m.clampEntrySelection()
+		}
+	case "pgup", "pgdown", "home", "end":
+		m.pageList(msg.String())
+	case "i":
+		// Show metadata of the selected entry next to the listing
+		m.showDetail = !m.showDetail
+		m.detailPath = ""
+		m.detail = entryDetail{}
+	case "z":
+		// Switch between every entry and the largest ones
+		if !m.inOverviewMode() {
+			m.toggleCompact()
 		}
 	case "enter", "right", "l":
 		if m.showLargeFiles {
@@ -622,61 +1094,11 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m.enterSelectedDir()
 	case "b", "left", "h":
-		if m.showLargeFiles {
-			m.showLargeFiles = false
-			return m, nil
-		}
-		if len(m.history) == 0 {
-			// Return to overview if at top level
-			if !m.inOverviewMode() {
-				return m, m.switchToOverviewMode()
-			}
-			return m, nil
-		}
-		last := m.history[len(m.history)-1]
-		m.history = m.history[:len(m.history)-1]
-		m.path = last.Path
-		m.selected = last.Selected
-		m.offset = last.EntryOffset
-		m.largeSelected = last.LargeSelected
-		m.largeOffset = last.LargeOffset
-		m.isOverview = last.IsOverview
-		if last.Dirty {
-			// If returning to overview mode, refresh overview entries instead of scanning
-			if last.IsOverview {
-				m.hydrateOverviewEntries()
-				m.totalSize = sumKnownEntrySizes(m.entries)
-				m.status = "Ready"
-				m.scanning = false
-				if nextPendingOverviewIndex(m.entries) >= 0 {
-					m.overviewScanning = true
-					return m, m.scheduleOverviewScans()
-				}
-				return m, nil
-			}
-			m.status = "Scanning..."
-			m.scanning = true
-			return m, tea.Batch(m.scanCmd(m.path), tickCmd())
-		}
-		m.entries = last.Entries
-		m.largeFiles = last.LargeFiles
-		m.totalSize = last.TotalSize
-		m.clampEntrySelection()
-		m.clampLargeSelection()
-		if len(m.entries) == 0 {
-			m.selected = 0
-		} else if m.selected >= len(m.entries) {
-			m.selected = len(m.entries) - 1
-		}
-		if m.selected < 0 {
-			m.selected = 0
-		}
-		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
-		m.scanning = false
-		return m, nil
+		return m.goBack()
 	case "r":
 		// Invalidate cache before rescanning to ensure fresh data
 		invalidateCache(m.path)
+		resetRules()
 		m.status = "Refreshing..."
 		m.scanning = true
 		// Reset scan counters for refresh
@@ -686,7 +1108,31 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if m.currentPath != nil {
 			*m.currentPath = ""
 		}
-		return m, tea.Batch(m.scanCmd(m.path), tickCmd())
+		return m, tea.Batch(m.startScan(m.path), tickCmd())
+	case "W":
+		// Keep the listing current as files change
+		return m, m.toggleWatch()
+	case "M":
+		// Toggle scanning into network and FUSE mounts
+		if m.inOverviewMode() {
+			return m, nil
+		}
+		m.scanOpts.IncludeRemote = !m.scanOpts.IncludeRemote
+		if m.scanOpts.IncludeRemote {
+			m.status = "Including network/FUSE mounts..."
+		} else {
+			m.status = "Skipping network/FUSE mounts..."
+		}
+		// Listings cached in memory were built with the old setting
+		m.cache = make(map[string]historyEntry)
+		m.scanning = true
+		atomic.StoreInt64(m.filesScanned, 0)
+		atomic.StoreInt64(m.dirsScanned, 0)
+		atomic.StoreInt64(m.bytesScanned, 0)
+		if m.currentPath != nil {
+			*m.currentPath = ""
+		}
+		return m, tea.Batch(m.startScan(m.path), tickCmd())
 	case "t", "T":
 		// Don't allow switching to large files view in overview mode
 		if !m.inOverviewMode() {
@@ -698,69 +1144,205 @@ func (m model) updateKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	case "o":
 		// Open selected entry
-		if m.showLargeFiles {
-			if len(m.largeFiles) > 0 {
-				selected := m.largeFiles[m.largeSelected]
-				go func(path string) {
-					ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
-					defer cancel()
-					_ = exec.CommandContext(ctx, "open", path).Run()
-				}(selected.Path)
-				m.status = fmt.Sprintf("Opening %s...", selected.Name)
-			}
-		} else if len(m.entries) > 0 {
-			selected := m.entries[m.selected]
-			go func(path string) {
-				ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
-				defer cancel()
-				_ = exec.CommandContext(ctx, "open", path).Run()
-			}(selected.Path)
-			m.status = fmt.Sprintf("Opening %s...", selected.Name)
-		}
+		return m, m.openSelected(false)
 	case "f", "F":
-		// Reveal selected entry in Finder
-		if m.showLargeFiles {
-			if len(m.largeFiles) > 0 {
-				selected := m.largeFiles[m.largeSelected]
-				go func(path string) {
-					ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
-					defer cancel()
-					_ = exec.CommandContext(ctx, "open", "-R", path).Run()
-				}(selected.Path)
-				m.status = fmt.Sprintf("Showing %s in Finder...", selected.Name)
-			}
-		} else if len(m.entries) > 0 {
-			selected := m.entries[m.selected]
-			go func(path string) {
-				ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
-				defer cancel()
-				_ = exec.CommandContext(ctx, "open", "-R", path).Run()
-			}(selected.Path)
-			m.status = fmt.Sprintf("Showing %s in Finder...", selected.Name)
-		}
-	case "delete", "backspace":
-		// Delete selected file or directory
-		if m.showLargeFiles {
+		// Reveal selected entry in the file manager
+		return m, m.openSelected(true)
+	case "y":
+		// Copy the selected path
+		return m, m.copySelected(false)
+	case "Y":
+		// Copy the current directory
+		if m.inOverviewMode() {
+			return m, nil
+		}
+		return m, m.copySelected(true)
+	case "delete", "backspace", "D":
+		// Move selected (or all marked) entries to Trash, or delete them for good with D
+		m.deletePermanent = msg.String() == "D"
+		var targets []dirEntry
+		if marked := m.markedTargets(); len(marked) > 0 {
+			targets = marked
+		} else if m.showLargeFiles {
 			if len(m.largeFiles) > 0 {
 				selected := m.largeFiles[m.largeSelected]
-				m.deleteConfirm = true
-				m.deleteTarget = &dirEntry{
+				targets = []dirEntry{{
 					Name:  selected.Name,
 					Path:  selected.Path,
 					Size:  selected.Size,
 					IsDir: false,
-				}
+				}}
 			}
 		} else if len(m.entries) > 0 && !m.inOverviewMode() {
-			selected := m.entries[m.selected]
-			m.deleteConfirm = true
-			m.deleteTarget = &selected
+			targets = []dirEntry{m.entries[m.selected]}
+		}
+		if len(targets) == 0 {
+			return m, nil
+		}
+		m.requestDelete(targets, m.deletePermanent)
+	}
+	return m, nil
+}
+
+// requestDelete asks to confirm moving targets to the Trash, or deleting them
+// for good when permanent is set.
+func (m *model) requestDelete(targets []dirEntry, permanent bool) {
+	m.deletePermanent = permanent
+	// Protected and whitelisted paths are refused up front, with the reason
+	allowed, refused := partitionDeleteTargets(targets)
+	m.deleteFailures = refused
+	if len(allowed) == 0 {
+		m.status = summarizeRefusal(refused, len(targets))
+		return
+	}
+	if len(refused) > 0 {
+		m.status = fmt.Sprintf("%d protected items left out", len(refused))
+	}
+	m.deleteConfirm = true
+	m.deleteTargets = allowed
+	m.deletePreview = nil
+}
+
+// summarizeDelete builds the status line for a finished (batch) delete.
+func summarizeDelete(succeeded, failed []deleteItemResult, permanent bool, count int64) string {
+	var done string
+	switch {
+	case len(succeeded) == 0:
+		done = ""
+	case permanent:
+		done = fmt.Sprintf("Deleted %d items", count)
+	case len(succeeded) == 1:
+		done = fmt.Sprintf("Moved %s to Trash", displayPath(succeeded[0].path))
+	default:
+		done = fmt.Sprintf("Moved %d items to Trash", len(succeeded))
+	}
+
+	if len(failed) == 0 {
+		return done
+	}
+	verb := "move to Trash"
+	if permanent {
+		verb = "delete"
+	}
+	failure := fmt.Sprintf("Failed to %s %d of %d: %v", verb, len(failed), len(succeeded)+len(failed), failed[0].err)
+	if len(succeeded)+len(failed) == 1 {
+		failure = fmt.Sprintf("Failed to %s: %v", verb, failed[0].err)
+	}
+	if done == "" {
+		return failure
+	}
+	return done + "; " + failure
+}
+
+// summarizeRefusal explains why nothing was deleted.
+func summarizeRefusal(refused []deleteItemResult, total int) string {
+	if total == 1 {
+		return fmt.Sprintf("Refused to delete: %v", refused[0].err)
+	}
+	return fmt.Sprintf("Refused to delete all %d items, they are protected", total)
+}
+
+// updateJournalKey handles keys while the deletion history is shown.
+func (m model) updateJournalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "H", "b", "left", "h":
+		m.showJournal = false
+	case "up", "k":
+		if m.journalSelected > 0 {
+			m.journalSelected--
+		}
+		m.clampJournalSelection()
+	case "down", "j":
+		if m.journalSelected < len(m.journal)-1 {
+			m.journalSelected++
+		}
+		m.clampJournalSelection()
+	case "enter", "u":
+		// Restore the selected deletion
+		if len(m.journal) == 0 {
+			return m, nil
+		}
+		record := m.journal[m.journalSelected]
+		if !record.restorable() {
+			m.status = fmt.Sprintf("%s cannot be restored (%s)", displayPath(record.Path), journalStateLabel(record))
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Restoring %s...", displayPath(record.Path))
+		return m, restoreDeletionsCmd([]deletionRecord{record})
+	case "U":
+		// Restore the last N deletions, from the newest down to the selected one
+		if len(m.journal) == 0 {
+			return m, nil
+		}
+		var targets []deletionRecord
+		for _, record := range m.journal[:m.journalSelected+1] {
+			if record.restorable() {
+				targets = append(targets, record)
+			}
 		}
+		if len(targets) == 0 {
+			m.status = "Nothing to restore"
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Restoring %d items...", len(targets))
+		return m, restoreDeletionsCmd(targets)
 	}
 	return m, nil
 }
 
+func (m *model) clampJournalSelection() {
+	if len(m.journal) == 0 {
+		m.journalSelected = 0
+		m.journalOffset = 0
+		return
+	}
+	if m.journalSelected >= len(m.journal) {
+		m.journalSelected = len(m.journal) - 1
+	}
+	if m.journalSelected < 0 {
+		m.journalSelected = 0
+	}
+	viewport := calculateViewport(m.height, true)
+	if m.journalSelected < m.journalOffset {
+		m.journalOffset = m.journalSelected
+	}
+	if m.journalSelected >= m.journalOffset+viewport {
+		m.journalOffset = m.journalSelected - viewport + 1
+	}
+}
+
+// rescanAfterChange marks every cached level dirty after files were removed
+// or restored, and rescans the current directory.
+func (m *model) rescanAfterChange() tea.Cmd {
+	// Mark all caches as dirty
+	for i := range m.history {
+		m.history[i].Dirty = true
+	}
+	for path := range m.cache {
+		entry := m.cache[path]
+		entry.Dirty = true
+		m.cache[path] = entry
+	}
+	if m.inOverviewMode() {
+		return nil
+	}
+	// Refresh the view
+	m.scanning = true
+	// Reset scan counters for rescan
+	atomic.StoreInt64(m.filesScanned, 0)
+	atomic.StoreInt64(m.dirsScanned, 0)
+	atomic.StoreInt64(m.bytesScanned, 0)
+	if m.currentPath != nil {
+		*m.currentPath = ""
+	}
+	return tea.Batch(m.startScan(m.path), tickCmd())
+}
+
 func (m *model) switchToOverviewMode() tea.Cmd {
+	m.clearFilter()
+	m.cancelScan()
 	m.isOverview = true
 	m.path = "/"
 	m.scanning = false
@@ -769,7 +1351,8 @@ func (m *model) switchToOverviewMode() tea.Cmd {
 	m.largeSelected = 0
 	m.largeOffset = 0
 	m.deleteConfirm = false
-	m.deleteTarget = nil
+	m.deleteTargets = nil
+	m.deletePreview = nil
 	m.selected = 0
 	m.offset = 0
 	m.hydrateOverviewEntries()
@@ -789,56 +1372,179 @@ func (m model) enterSelectedDir() (tea.Model, tea.Cmd) {
 	selected := m.entries[m.selected]
 	if selected.IsDir {
 		// Always save current state to history (including overview mode)
+		return m.openDir(selected.Path, true)
+	}
+	m.status = fmt.Sprintf("File: %s (%s)", selected.Name, humanizeBytes(selected.Size))
+	return m, nil
+}
+
+// openDir shows path, from the cache or the last scan when they have it and
+// by scanning it otherwise. With remember set, ← returns to where we are.
+func (m model) openDir(path string, remember bool) (tea.Model, tea.Cmd) {
+	m.clearFilter()
+	m.cancelScan()
+	m.cancelOverviewScans()
+	if remember {
 		m.history = append(m.history, snapshotFromModel(m))
-		m.path = selected.Path
-		m.selected = 0
-		m.offset = 0
-		m.status = "Scanning..."
-		m.scanning = true
-		m.isOverview = false
+	}
+	m.path = path
+	m.selected = 0
+	m.offset = 0
+	m.showLargeFiles = false
+	m.status = "Scanning..."
+	m.scanning = true
+	m.isOverview = false
+	if m.scanRoot == "" || !isSameOrBelow(path, m.scanRoot) {
+		m.scanRoot = path
+	}
 
-		// Reset scan counters for new scan
-		atomic.StoreInt64(m.filesScanned, 0)
-		atomic.StoreInt64(m.dirsScanned, 0)
-		atomic.StoreInt64(m.bytesScanned, 0)
-		if m.currentPath != nil {
-			*m.currentPath = ""
+	// Reset scan counters for new scan
+	atomic.StoreInt64(m.filesScanned, 0)
+	atomic.StoreInt64(m.dirsScanned, 0)
+	atomic.StoreInt64(m.bytesScanned, 0)
+	if m.currentPath != nil {
+		*m.currentPath = ""
+	}
+
+	if cached, ok := m.cache[m.path]; ok && !cached.Dirty {
+		m.entries = cloneDirEntries(cached.Entries)
+		m.largeFiles = cloneFileEntries(cached.LargeFiles)
+		m.totalSize = cached.TotalSize
+		m.tree = cached.Tree
+		m.selected = cached.Selected
+		m.offset = cached.EntryOffset
+		m.largeSelected = cached.LargeSelected
+		m.largeOffset = cached.LargeOffset
+		m.applySort()
+		m.selectPending()
+		m.clampLargeSelection()
+		m.status = fmt.Sprintf("Cached view for %s", displayPath(m.path))
+		m.scanning = false
+		return m, nil
+	}
+
+	// Reuse the subtree from the last scan when it still has all its children
+	if node := m.tree.find(m.path); node.hasListing() && !node.Truncated {
+		result := node.result()
+		m.entries = result.Entries
+		m.largeFiles = result.LargeFiles
+		m.totalSize = result.TotalSize
+		m.largeSelected = 0
+		m.largeOffset = 0
+		m.applySort()
+		m.selectPending()
+		m.clampLargeSelection()
+		m.cache[m.path] = cacheSnapshot(m)
+		m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+		m.scanning = false
+		return m, nil
+	}
+	return m, tea.Batch(m.startScan(m.path), tickCmd())
+}
+
+// selectPending moves the selection to pendingSelect once the listing that
+// contains it is loaded, e.g. the directory we came up from.
+func (m *model) selectPending() {
+	if m.pendingSelect == "" {
+		return
+	}
+	for i, entry := range m.entries {
+		if entry.Path == m.pendingSelect {
+			m.selected = i
+			break
 		}
+	}
+	m.pendingSelect = ""
+	m.clampEntrySelection()
+}
 
-		if cached, ok := m.cache[m.path]; ok && !cached.Dirty {
-			m.entries = cloneDirEntries(cached.Entries)
-			m.largeFiles = cloneFileEntries(cached.LargeFiles)
-			m.totalSize = cached.TotalSize
-			m.selected = cached.Selected
-			m.offset = cached.EntryOffset
-			m.largeSelected = cached.LargeSelected
-			m.largeOffset = cached.LargeOffset
-			m.clampEntrySelection()
-			m.clampLargeSelection()
-			m.status = fmt.Sprintf("Cached view for %s", displayPath(m.path))
+// goBack leaves the large files view, or returns to the previous directory.
+func (m model) goBack() (tea.Model, tea.Cmd) {
+	if m.showLargeFiles {
+		m.showLargeFiles = false
+		return m, nil
+	}
+	m.clearFilter()
+	m.cancelScan()
+	m.pendingSelect = ""
+	if len(m.history) == 0 {
+		switch {
+		case m.inOverviewMode():
+			return m, nil
+		case m.path == "/":
+			// Return to overview once there is nothing above
+			return m, m.switchToOverviewMode()
+		default:
+			// Keep going up past the directory we started in
+			m.pendingSelect = m.path
+			return m.openDir(filepath.Dir(m.path), false)
+		}
+	}
+	last := m.history[len(m.history)-1]
+	m.history = m.history[:len(m.history)-1]
+	m.path = last.Path
+	m.selected = last.Selected
+	m.offset = last.EntryOffset
+	m.largeSelected = last.LargeSelected
+	m.largeOffset = last.LargeOffset
+	m.isOverview = last.IsOverview
+	m.sortOrder = last.SortOrder
+	if last.Dirty {
+		// If returning to overview mode, refresh overview entries instead of scanning
+		if last.IsOverview {
+			m.hydrateOverviewEntries()
+			m.totalSize = sumKnownEntrySizes(m.entries)
+			m.status = "Ready"
 			m.scanning = false
+			if nextPendingOverviewIndex(m.entries) >= 0 {
+				m.overviewScanning = true
+				return m, m.scheduleOverviewScans()
+			}
 			return m, nil
 		}
-		return m, tea.Batch(m.scanCmd(m.path), tickCmd())
+		m.status = "Scanning..."
+		m.scanning = true
+		return m, tea.Batch(m.startScan(m.path), tickCmd())
+	}
+	m.entries = last.Entries
+	m.largeFiles = last.LargeFiles
+	m.totalSize = last.TotalSize
+	m.tree = last.Tree
+	m.applySort()
+	m.clampLargeSelection()
+	if len(m.entries) == 0 {
+		m.selected = 0
+	} else if m.selected >= len(m.entries) {
+		m.selected = len(m.entries) - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+	m.status = fmt.Sprintf("Scanned %s", humanizeBytes(m.totalSize))
+	m.scanning = false
+	if last.IsOverview && nextPendingOverviewIndex(m.entries) >= 0 {
+		// Sizing was stopped when the overview was left
+		m.overviewScanning = true
+		return m, m.scheduleOverviewScans()
 	}
-	m.status = fmt.Sprintf("File: %s (%s)", selected.Name, humanizeBytes(selected.Size))
 	return m, nil
 }
 
 func (m *model) clampEntrySelection() {
-	if len(m.entries) == 0 {
+	shown := m.shownEntries()
+	if shown == 0 {
 		m.selected = 0
 		m.offset = 0
 		return
 	}
-	if m.selected >= len(m.entries) {
-		m.selected = len(m.entries) - 1
+	if m.selected >= shown {
+		m.selected = shown - 1
 	}
 	if m.selected < 0 {
 		m.selected = 0
 	}
 	viewport := calculateViewport(m.height, false)
-	maxOffset := len(m.entries) - viewport
+	maxOffset := m.listRows() - viewport
 	if maxOffset < 0 {
 		maxOffset = 0
 	}
@@ -851,6 +1557,10 @@ func (m *model) clampEntrySelection() {
 	if m.selected >= m.offset+viewport {
 		m.offset = m.selected - viewport + 1
 	}
+	if m.selected == shown-1 && viewport > 1 {
+		// Bring the "other items" row below the last entry into view
+		m.offset = max(m.offset, maxOffset)
+	}
 }
 
 func (m *model) clampLargeSelection() {
@@ -900,6 +1610,15 @@ func nextPendingOverviewIndex(entries []dirEntry) int {
 	return -1
 }
 
+func hasMeasuredOverviewEntries(entries []dirEntry) bool {
+	for _, entry := range entries {
+		if entry.Size >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func hasPendingOverviewEntries(entries []dirEntry) bool {
 	for _, entry := range entries {
 		if entry.Size < 0 {
@@ -932,6 +1651,23 @@ func (m *model) removePathFromView(path string) {
 		}
 	}
 
+	if m.filterOn {
+		filtered := m.filterEntries[:0]
+		for _, entry := range m.filterEntries {
+			if entry.Path != path {
+				filtered = append(filtered, entry)
+			}
+		}
+		m.filterEntries = filtered
+		filteredLarge := m.filterLargeFiles[:0]
+		for _, file := range m.filterLargeFiles {
+			if file.Path != path {
+				filteredLarge = append(filteredLarge, file)
+			}
+		}
+		m.filterLargeFiles = filteredLarge
+	}
+
 	if removedSize > 0 {
 		if removedSize > m.totalSize {
 			m.totalSize = 0
@@ -943,9 +1679,9 @@ func (m *model) removePathFromView(path string) {
 	m.clampLargeSelection()
 }
 
-func scanOverviewPathCmd(path string, index int) tea.Cmd {
+func scanOverviewPathCmd(ctx context.Context, path string, index int, progress sizeProgress) tea.Cmd {
 	return func() tea.Msg {
-		size, err := measureOverviewSize(path)
+		size, err := measureOverviewSize(ctx, path, progress)
 		return overviewSizeMsg{
 			Path:  path,
 			Index: index,
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// scanOptions controls which filesystems a scan may cross into.
+type scanOptions struct {
+	OneFileSystem bool // Like du -x: don't descend into other mounts
+	IncludeRemote bool // Descend into network and FUSE mounts
+}
+
+// remoteFSTypes are network filesystems that are slow or costly to walk.
+// FUSE mounts ("fuse", "fuse.sshfs", ...) are matched separately.
+var remoteFSTypes = map[string]bool{
+	"nfs":       true,
+	"nfs4":      true,
+	"cifs":      true,
+	"smb3":      true,
+	"smbfs":     true,
+	"afpfs":     true,
+	"webdav":    true,
+	"davfs":     true,
+	"9p":        true,
+	"ceph":      true,
+	"glusterfs": true,
+	"afs":       true,
+	"sshfs":     true,
+	"osxfuse":   true,
+	"macfuse":   true,
+}
+
+func isRemoteFSType(fsType string) bool {
+	if remoteFSTypes[fsType] {
+		return true
+	}
+	// fuseblk backs local disks (ntfs-3g, exfat) and is walked like any other
+	return fsType == "fuse" || strings.HasPrefix(fsType, "fuse.")
+}
+
+// mountSkipReason returns why a mount point of the given type is not scanned,
+// or "" when it should be.
+func (o scanOptions) mountSkipReason(fsType string) string {
+	switch {
+	case o.OneFileSystem:
+		return "other filesystem"
+	case !o.IncludeRemote && isRemoteFSType(fsType):
+		return "network/FUSE"
+	default:
+		return ""
+	}
+}
+
+// mountEntry is one line of the mount table.
+type mountEntry struct {
+	Point   string
+	FSType  string
+	Options []string // Per-mount options such as "rw" or "noatime"
+}
+
+// mountFSType looks up the filesystem type mounted at path. It returns "" when
+// the mount table can't be read.
+func mountFSType(path string) string {
+	path = filepath.Clean(path)
+	// Later lines win: they are mounted on top of earlier ones
+	fsType := ""
+	for _, mount := range readMounts() {
+		if mount.Point == path {
+			fsType = mount.FSType
+		}
+	}
+	return fsType
+}
+
+// mountContaining returns the mount that path lives on.
+func mountContaining(path string) (mountEntry, bool) {
+	path = filepath.Clean(path)
+	var found mountEntry
+	ok := false
+	for _, mount := range readMounts() {
+		if isSameOrBelow(path, mount.Point) && (!ok || len(mount.Point) >= len(found.Point)) {
+			found, ok = mount, true
+		}
+	}
+	return found, ok
+}
+
+// readMounts reads /proc/self/mountinfo, or `mount` output on macOS.
+func readMounts() []mountEntry {
+	if runtime.GOOS == "darwin" {
+		return readDarwinMounts()
+	}
+	file, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var mounts []mountEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// id parent major:minor root mountpoint options [optional...] - fstype source superoptions
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		mount := mountEntry{Point: unescapeMountPath(fields[4]), Options: strings.Split(fields[5], ",")}
+		for i := 6; i < len(fields)-1; i++ {
+			if fields[i] == "-" {
+				mount.FSType = fields[i+1]
+				break
+			}
+		}
+		mounts = append(mounts, mount)
+	}
+	return mounts
+}
+
+// readDarwinMounts parses `mount` output: "/dev/disk3s1 on /Volumes/X (apfs, local, ...)".
+func readDarwinMounts() []mountEntry {
+	ctx, cancel := context.WithTimeout(context.Background(), mdlsTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, "mount").Output()
+	if err != nil {
+		return nil
+	}
+	var mounts []mountEntry
+	for _, line := range bytes.Split(output, []byte("\n")) {
+		text := string(line)
+		on := strings.Index(text, " on ")
+		open := strings.LastIndex(text, " (")
+		if on < 0 || open < on {
+			continue
+		}
+		attrs := strings.Split(strings.TrimSuffix(text[open+2:], ")"), ",")
+		for i := range attrs {
+			attrs[i] = strings.TrimSpace(attrs[i])
+		}
+		mounts = append(mounts, mountEntry{Point: text[on+4 : open], FSType: attrs[0], Options: attrs[1:]})
+	}
+	return mounts
+}
+
+// atimeMode reports how access times are kept on the filesystem of path:
+// "noatime" when they are not updated at all, "relatime" when at most once a
+// day, "" when on every read or unknown.
+func atimeMode(path string) string {
+	mount, ok := mountContaining(path)
+	if !ok {
+		return ""
+	}
+	mode := ""
+	for _, option := range mount.Options {
+		switch option {
+		case "noatime":
+			return option
+		case "relatime":
+			mode = option
+		}
+	}
+	return mode
+}
+
+// unescapeMountPath decodes the octal escapes (\040 for space) used in mountinfo.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// mountLabel describes a mount point entry for the listing, e.g. "[nfs4, not scanned]".
+func mountLabel(entry dirEntry) string {
+	if !entry.IsMount {
+		return ""
+	}
+	fsType := entry.MountFS
+	if fsType == "" {
+		fsType = "mount"
+	}
+	if entry.MountSkip != "" {
+		return fmt.Sprintf("[%s, not scanned: %s]", fsType, entry.MountSkip)
+	}
+	return fmt.Sprintf("[%s]", fsType)
+}
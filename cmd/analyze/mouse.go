@@ -0,0 +1,101 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateMouse handles the wheel and clicks. The listings scroll and select
+// like the arrow keys; views on top of the listing only scroll.
+func (m model) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.deleteConfirm || m.deleting || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	overlay := m.showJournal || m.showSnapshots || m.showDiff || m.showTypes || m.showStale
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		key := tea.KeyMsg{Type: tea.KeyDown}
+		delta := wheelStep
+		if msg.Button == tea.MouseButtonWheelUp {
+			key = tea.KeyMsg{Type: tea.KeyUp}
+			delta = -wheelStep
+		}
+		if overlay {
+			var next tea.Model = m
+			for i := 0; i < wheelStep; i++ {
+				next, _ = next.(model).updateKey(key)
+			}
+			return next, nil
+		}
+		m.scrollList(delta)
+		return m, nil
+	case tea.MouseButtonLeft:
+		if overlay || m.detailReplacesList() {
+			return m, nil
+		}
+		if msg.Y == breadcrumbLine {
+			if target, ok := m.breadcrumbTarget(msg.X); ok {
+				m.crumbFocus = false
+				return m.goUpTo(target)
+			}
+			return m, nil
+		}
+		return m.clickRow(msg.Y)
+	}
+	return m, nil
+}
+
+// scrollList moves the viewport of the listing by delta rows, taking the
+// selection along when it would scroll out of view.
+func (m *model) scrollList(delta int) {
+	switch {
+	case m.scanning:
+	case m.showLargeFiles:
+		viewport := calculateViewport(m.height, true)
+		m.largeOffset = max(min(m.largeOffset+delta, len(m.largeFiles)-viewport), 0)
+		m.largeSelected = min(max(m.largeSelected, m.largeOffset), m.largeOffset+viewport-1)
+		m.clampLargeSelection()
+	case m.inOverviewMode():
+		// Every location is on screen, so the wheel moves the selection
+		m.selected = max(min(m.selected+delta/wheelStep, len(m.entries)-1), 0)
+	default:
+		viewport := calculateViewport(m.height, false)
+		m.offset = max(min(m.offset+delta, m.listRows()-viewport), 0)
+		m.selected = min(max(m.selected, m.offset), m.offset+viewport-1)
+		m.clampEntrySelection()
+	}
+}
+
+// clickRow selects the row on screen line y. A second click on the same row
+// soon after enters it, like Enter.
+func (m model) clickRow(y int) (tea.Model, tea.Cmd) {
+	index, ok := m.listGeometry().row(y)
+	if !ok {
+		return m, nil
+	}
+	if !m.showLargeFiles && index >= m.shownEntries() {
+		// The "other items" row of the compact view
+		m.toggleCompact()
+		return m, nil
+	}
+	var path string
+	if m.showLargeFiles {
+		m.largeSelected = index
+		path = m.largeFiles[index].Path
+	} else {
+		m.selected = index
+		path = m.entries[index].Path
+	}
+
+	now := time.Now()
+	double := path == m.lastClickPath && now.Sub(m.lastClickAt) <= doubleClickTime
+	m.lastClickPath = path
+	m.lastClickAt = now
+	if double && !m.showLargeFiles {
+		m.lastClickPath = ""
+		return m.enterSelectedDir()
+	}
+	return m, nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const maxGotoHints = 8 // Completions listed after the go to prompt
+
+// breadcrumb is one directory of the path in the header.
+type breadcrumb struct {
+	Label string
+	Path  string
+}
+
+// breadcrumbs splits m.path into its ancestors, starting at / or ~.
+func (m model) breadcrumbs() []breadcrumb {
+	root := breadcrumb{Label: "/", Path: "/"}
+	rest := strings.TrimPrefix(m.path, "/")
+	if home, err := os.UserHomeDir(); err == nil && home != "/" && home != "" {
+		if m.path == home || strings.HasPrefix(m.path, home+"/") {
+			root = breadcrumb{Label: "~", Path: home}
+			rest = strings.TrimPrefix(strings.TrimPrefix(m.path, home), "/")
+		}
+	}
+
+	crumbs := []breadcrumb{root}
+	current := root.Path
+	for _, name := range strings.Split(rest, "/") {
+		if name == "" {
+			continue
+		}
+		current = filepath.Join(current, name)
+		crumbs = append(crumbs, breadcrumb{Label: name, Path: current})
+	}
+	return crumbs
+}
+
+// breadcrumbSeparator is drawn before crumb i: none after the leading /.
+func breadcrumbSeparator(crumbs []breadcrumb, i int) string {
+	if i == 0 || crumbs[i-1].Label == "/" {
+		return ""
+	}
+	return "/"
+}
+
+// goUpTo shows target, a directory above m.path. Directories entered on the
+// way here are returned to as with ←, anything above is opened.
+func (m model) goUpTo(target string) (tea.Model, tea.Cmd) {
+	m.showLargeFiles = false
+	if target == m.path {
+		return m, nil
+	}
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].Path == target && !m.history[i].IsOverview {
+			m.history = m.history[:i+1]
+			return m.goBack()
+		}
+	}
+
+	// ← from target goes where it would have gone from here, skipping the
+	// directories between target and m.path
+	for len(m.history) > 0 && isSameOrBelow(m.history[len(m.history)-1].Path, target) {
+		m.history = m.history[:len(m.history)-1]
+	}
+	next := m.path
+	for filepath.Dir(next) != target && filepath.Dir(next) != next {
+		next = filepath.Dir(next)
+	}
+	m.pendingSelect = next
+	return m.openDir(target, false)
+}
+
+// updateCrumbKey handles keys while the path in the header has focus.
+func (m model) updateCrumbKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	crumbs := m.breadcrumbs()
+	switch msg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc", "B":
+		m.crumbFocus = false
+	case "left", "h":
+		if m.crumbSelected > 0 {
+			m.crumbSelected--
+		}
+	case "right", "l":
+		if m.crumbSelected < len(crumbs)-1 {
+			m.crumbSelected++
+		}
+	case "home":
+		m.crumbSelected = 0
+	case "end":
+		m.crumbSelected = len(crumbs) - 1
+	case "enter":
+		m.crumbFocus = false
+		return m.goUpTo(crumbs[min(m.crumbSelected, len(crumbs)-1)].Path)
+	}
+	return m, nil
+}
+
+// focusBreadcrumbs gives the path in the header the keyboard, starting at
+// the parent directory.
+func (m *model) focusBreadcrumbs() {
+	if m.inOverviewMode() {
+		return
+	}
+	m.crumbFocus = true
+	m.crumbSelected = max(len(m.breadcrumbs())-2, 0)
+}
+
+// startGoto opens the go to path prompt.
+func (m *model) startGoto() {
+	m.gotoTyping = true
+	m.gotoInput = ""
+	m.gotoHint = ""
+}
+
+// updateGotoKey handles keys while the go to path prompt has focus.
+func (m model) updateGotoKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.gotoTyping = false
+		return m, nil
+	case tea.KeyEnter:
+		m.gotoTyping = false
+		if strings.TrimSpace(m.gotoInput) == "" {
+			return m, nil
+		}
+		return m.gotoPath(m.gotoInput)
+	case tea.KeyTab:
+		var candidates []string
+		m.gotoInput, candidates = completePath(m.gotoInput, m.path)
+		m.gotoHint = ""
+		if len(candidates) > 1 {
+			if len(candidates) > maxGotoHints {
+				candidates = append(candidates[:maxGotoHints], "…")
+			}
+			m.gotoHint = strings.Join(candidates, "  ")
+		}
+		return m, nil
+	case tea.KeyBackspace:
+		if runes := []rune(m.gotoInput); len(runes) > 0 {
+			m.gotoInput = string(runes[:len(runes)-1])
+		}
+		m.gotoHint = ""
+		return m, nil
+	case tea.KeySpace:
+		m.gotoInput += " "
+		return m, nil
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if unicode.IsPrint(r) {
+				m.gotoInput += string(r)
+			}
+		}
+		m.gotoHint = ""
+		return m, nil
+	}
+	return m, nil
+}
+
+// gotoPath shows the directory typed at the prompt, or the directory of a
+// file with the file selected. ← returns to where we were.
+func (m model) gotoPath(input string) (tea.Model, tea.Cmd) {
+	path := expandPath(input, m.path)
+	info, err := os.Stat(path)
+	if err != nil {
+		m.status = fmt.Sprintf("Cannot go to %s: %v", input, err)
+		return m, nil
+	}
+	if !info.IsDir() {
+		m.pendingSelect = path
+		path = filepath.Dir(path)
+	}
+	if path == m.path && !m.inOverviewMode() {
+		m.selectPending()
+		return m, nil
+	}
+	return m.openDir(path, true)
+}
+
+// expandPath resolves what was typed at the prompt: ~ is the home directory
+// and relative paths start at base.
+func expandPath(input, base string) string {
+	input = strings.TrimSpace(input)
+	if input == "~" || strings.HasPrefix(input, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			input = home + input[1:]
+		}
+	}
+	if !filepath.IsAbs(input) {
+		input = filepath.Join(base, input)
+	}
+	return filepath.Clean(input)
+}
+
+// completePath completes the last name of input to the directories that
+// start with it, as far as they agree, and returns the candidates. input is
+// kept as typed, so ~ and relative paths stay that way.
+func completePath(input, base string) (string, []string) {
+	if input == "~" {
+		return "~/", nil
+	}
+	dirPart, prefix := "", input
+	if i := strings.LastIndex(input, "/"); i >= 0 {
+		dirPart, prefix = input[:i+1], input[i+1:]
+	}
+	dir := base
+	if dirPart != "" {
+		dir = expandPath(dirPart, base)
+	}
+	children, err := os.ReadDir(dir)
+	if err != nil {
+		return input, nil
+	}
+
+	var names []string
+	for _, child := range children {
+		name := child.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		isDir := child.IsDir()
+		if child.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		if isDir {
+			names = append(names, name)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return input, nil
+	case 1:
+		return dirPart + names[0] + "/", names
+	}
+	sort.Strings(names)
+	common := names[0]
+	for _, name := range names[1:] {
+		for !strings.HasPrefix(name, common) {
+			common = common[:len(common)-1]
+		}
+	}
+	return dirPart + common, names
+}
+
+// gotoLine renders the prompt shown under the header while typing a path.
+func (m model) gotoLine() string {
+	hint := "Tab complete  |  Enter go  |  ESC cancel"
+	if m.gotoHint != "" {
+		hint = m.gotoHint
+	}
+	return fmt.Sprintf("%sGo to:%s %s▏  %s%s%s",
+		colorCyan, colorReset, m.gotoInput, colorGray, hint, colorReset)
+}
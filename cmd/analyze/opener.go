@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openerEnv names a command that replaces the platform opener, e.g.
+// MARMOT_OPENER="code -r". The path is appended as the last argument.
+const openerEnv = "MARMOT_OPENER"
+
+// opener opens files with their default application and shows them in the
+// file manager.
+type opener interface {
+	open(ctx context.Context, path string) error
+	reveal(ctx context.Context, path string) error
+	// manager names where reveal shows things, for the status line
+	manager() string
+}
+
+// darwinOpener uses open(1).
+type darwinOpener struct{}
+
+func (darwinOpener) open(ctx context.Context, path string) error {
+	return runOpener(ctx, "open", path)
+}
+
+func (darwinOpener) reveal(ctx context.Context, path string) error {
+	return runOpener(ctx, "open", "-R", path)
+}
+
+func (darwinOpener) manager() string { return "Finder" }
+
+// xdgOpener follows the freedesktop.org conventions: xdg-open to open, and
+// the FileManager1 D-Bus interface to reveal, which selects the item in
+// Nautilus, Dolphin, Nemo, Thunar and others.
+type xdgOpener struct{}
+
+func (xdgOpener) open(ctx context.Context, path string) error {
+	return runOpener(ctx, "xdg-open", path)
+}
+
+// reveal falls back to opening the parent directory when no file manager
+// answers on the session bus.
+func (o xdgOpener) reveal(ctx context.Context, path string) error {
+	uri := (&url.URL{Scheme: "file", Path: path}).String()
+	err := runOpener(ctx, "dbus-send", "--session", "--print-reply",
+		"--dest=org.freedesktop.FileManager1", "--type=method_call",
+		"/org/freedesktop/FileManager1", "org.freedesktop.FileManager1.ShowItems",
+		"array:string:"+uri, "string:")
+	if err == nil {
+		return nil
+	}
+	return o.open(ctx, filepath.Dir(path))
+}
+
+func (xdgOpener) manager() string { return "the file manager" }
+
+// commandOpener runs the command from $MARMOT_OPENER. It has no notion of
+// selecting an item, so reveal opens the parent directory with it.
+type commandOpener struct {
+	args []string
+}
+
+func (o commandOpener) open(ctx context.Context, path string) error {
+	return runOpener(ctx, o.args[0], append(o.args[1:], path)...)
+}
+
+func (o commandOpener) reveal(ctx context.Context, path string) error {
+	return o.open(ctx, filepath.Dir(path))
+}
+
+func (o commandOpener) manager() string { return o.args[0] }
+
+// defaultOpener picks $MARMOT_OPENER, then the platform's opener.
+func defaultOpener() opener {
+	if args := strings.Fields(os.Getenv(openerEnv)); len(args) > 0 {
+		return commandOpener{args: args}
+	}
+	if runtime.GOOS == "darwin" {
+		return darwinOpener{}
+	}
+	return xdgOpener{}
+}
+
+// runOpener runs an opener command, turning a failure into an error that
+// carries the command's own message.
+func runOpener(ctx context.Context, name string, args ...string) error {
+	if _, err := exec.LookPath(name); err != nil {
+		return fmt.Errorf("%s not found, set $%s", name, openerEnv)
+	}
+	cmd := exec.CommandContext(ctx, name, args...)
+	// stdout stays unset and stderr is only read until the opener exits: the
+	// application it starts inherits both, and waiting for the pipes to close
+	// would wait for the application
+	stderr := &cappedBuffer{limit: openerStderrLimit}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = openerWaitDelay
+	err := cmd.Run()
+	if err == nil || errors.Is(err, exec.ErrWaitDelay) {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s did not finish within %v", name, openCommandTimeout)
+	}
+	if message := firstLine(stderr.String()); message != "" {
+		return errors.New(message)
+	}
+	return fmt.Errorf("%s failed: %v", name, err)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and drops the rest.
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+type openResultMsg struct {
+	name   string
+	reveal bool
+	err    error
+}
+
+// openCmd opens path, or reveals it in the file manager, off the UI goroutine.
+func openCmd(path, name string, reveal bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), openCommandTimeout)
+		defer cancel()
+		o := defaultOpener()
+		var err error
+		if reveal {
+			err = o.reveal(ctx, path)
+		} else {
+			err = o.open(ctx, path)
+		}
+		return openResultMsg{name: name, reveal: reveal, err: err}
+	}
+}
+
+// selectedItem returns the entry under the cursor in the list on screen.
+func (m model) selectedItem() (name, path string, ok bool) {
+	switch {
+	case m.showLargeFiles:
+		if len(m.largeFiles) == 0 {
+			return "", "", false
+		}
+		selected := m.largeFiles[m.largeSelected]
+		return selected.Name, selected.Path, true
+	case len(m.entries) > 0:
+		selected := m.entries[m.selected]
+		return selected.Name, selected.Path, true
+	default:
+		return "", "", false
+	}
+}
+
+// openSelected opens the entry under the cursor, or reveals it.
+func (m *model) openSelected(reveal bool) tea.Cmd {
+	name, path, ok := m.selectedItem()
+	if !ok {
+		return nil
+	}
+	if reveal {
+		m.status = fmt.Sprintf("Showing %s in %s...", name, defaultOpener().manager())
+	} else {
+		m.status = fmt.Sprintf("Opening %s...", name)
+	}
+	return openCmd(path, name, reveal)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// deletePreview is a dry run of a delete: what the targets contain and what is
+// likely to get in the way.
+type deletePreview struct {
+	Files      int64
+	Dirs       int64
+	Bytes      int64
+	Largest    []fileEntry
+	Locked     pathSamples // In a directory we may not remove them from
+	OtherOwner pathSamples // Owned by another user
+	OtherFS    pathSamples // On another filesystem than the target
+	OpenFiles  pathSamples // Held open by a running process
+	OpenCheck  bool        // Whether open files could be looked up at all
+	Incomplete bool        // The walk hit previewTimeout
+}
+
+// pathSamples counts matching paths and keeps the first few, ready to print.
+type pathSamples struct {
+	Count   int64
+	Samples []string
+}
+
+func (p *pathSamples) add(path string) {
+	p.addNote(path, "")
+}
+
+func (p *pathSamples) addNote(path, note string) {
+	p.Count++
+	if len(p.Samples) < maxPreviewSamples {
+		sample := truncateMiddle(displayPath(path), 40)
+		if note != "" {
+			sample += " (" + note + ")"
+		}
+		p.Samples = append(p.Samples, sample)
+	}
+}
+
+type deletePreviewMsg struct {
+	targets []dirEntry
+	preview deletePreview
+}
+
+func previewDeleteCmd(targets []dirEntry) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), previewTimeout)
+		defer cancel()
+		return deletePreviewMsg{targets: targets, preview: buildDeletePreview(ctx, targets)}
+	}
+}
+
+// buildDeletePreview walks every target without modifying anything.
+func buildDeletePreview(ctx context.Context, targets []dirEntry) deletePreview {
+	var preview deletePreview
+	var large []fileEntry
+	uid := uint32(os.Geteuid())
+	openPaths, openCheck := openFilesUnder(targets)
+	preview.OpenCheck = openCheck
+	dirs := make(map[string]parentDir)
+
+	for _, target := range targets {
+		rootInfo, err := os.Lstat(target.Path)
+		if err != nil {
+			continue
+		}
+		rootDev, hasRootDev := deviceOf(rootInfo)
+
+		_ = filepath.WalkDir(target.Path, func(path string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				preview.Incomplete = true
+				return filepath.SkipAll
+			}
+			if err != nil {
+				// Unreadable directories can't be emptied either
+				preview.Locked.add(path)
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			stat, _ := info.Sys().(*syscall.Stat_t)
+			if stat != nil {
+				if stat.Uid != uid && uid != 0 {
+					preview.OtherOwner.add(path)
+				}
+				if hasRootDev && uint64(stat.Dev) != rootDev {
+					preview.OtherFS.add(path)
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+				}
+			}
+			if !canRemove(path, stat, uid, dirs) {
+				preview.Locked.add(path)
+			}
+			if owner, ok := openPaths[path]; ok {
+				preview.OpenFiles.addNote(path, owner)
+			}
+
+			if d.IsDir() {
+				preview.Dirs++
+				return nil
+			}
+			size := getActualFileSize(path, info)
+			preview.Files++
+			preview.Bytes += size
+			large = append(large, fileEntry{Name: d.Name(), Path: path, Size: size})
+			if len(large) > maxPreviewLargest*8 {
+				large = topLargeFiles(large, maxPreviewLargest)
+			}
+			return nil
+		})
+	}
+
+	preview.Largest = topLargeFiles(large, maxPreviewLargest)
+	return preview
+}
+
+// parentDir is what decides whether entries can be removed from a directory.
+type parentDir struct {
+	writable bool   // We may add and remove entries
+	sticky   bool   // Only owners may remove entries, as in /tmp
+	uid      uint32 // Owner of the directory
+}
+
+// canRemove reports whether path could be unlinked, which depends on its
+// directory rather than on path itself. dirs caches the directories seen.
+func canRemove(path string, stat *syscall.Stat_t, uid uint32, dirs map[string]parentDir) bool {
+	dir := filepath.Dir(path)
+	parent, ok := dirs[dir]
+	if !ok {
+		parent.writable = syscall.Access(dir, 2|1 /* W_OK|X_OK */) == nil
+		if info, err := os.Stat(dir); err == nil {
+			parent.sticky = info.Mode()&os.ModeSticky != 0
+			if dirStat, ok := info.Sys().(*syscall.Stat_t); ok {
+				parent.uid = dirStat.Uid
+			}
+		}
+		dirs[dir] = parent
+	}
+	if !parent.writable {
+		return false
+	}
+	if !parent.sticky || uid == 0 || parent.uid == uid {
+		return true
+	}
+	return stat != nil && stat.Uid == uid
+}
+
+// openFilesUnder maps paths inside targets to the process holding them open,
+// e.g. "firefox[1234]", by reading /proc/*/fd. Processes of other users are
+// only visible to root. The bool is false where /proc isn't available.
+func openFilesUnder(targets []dirEntry) (map[string]string, bool) {
+	if runtime.GOOS != "linux" {
+		return nil, false
+	}
+	procs, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, false
+	}
+
+	open := make(map[string]string)
+	for _, proc := range procs {
+		pid, err := strconv.Atoi(proc.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", proc.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "/") {
+				continue
+			}
+			link = strings.TrimSuffix(link, " (deleted)")
+			for _, target := range targets {
+				if isSameOrBelow(link, target.Path) {
+					if _, seen := open[link]; !seen {
+						open[link] = fmt.Sprintf("%s[%d]", processName(pid), pid)
+					}
+					break
+				}
+			}
+		}
+	}
+	return open, true
+}
+
+func processName(pid int) string {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return "pid"
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// previewLines renders the preview pane shown above the delete confirmation.
+func (p deletePreview) previewLines() []string {
+	var lines []string
+	summary := fmt.Sprintf("%sPreview:%s %s files, %s dirs, %s",
+		colorCyan, colorReset, formatNumber(p.Files), formatNumber(p.Dirs), humanizeBytes(p.Bytes))
+	if p.Incomplete {
+		summary += fmt.Sprintf("  %s(stopped after %v, partial)%s", colorGray, previewTimeout, colorReset)
+	}
+	lines = append(lines, summary)
+
+	if len(p.Largest) > 0 {
+		parts := make([]string, 0, len(p.Largest))
+		for _, file := range p.Largest {
+			parts = append(parts, fmt.Sprintf("%s %s", truncateMiddle(file.Name, 24), humanizeBytes(file.Size)))
+		}
+		lines = append(lines, fmt.Sprintf("  %sLargest:%s %s", colorGray, colorReset, strings.Join(parts, ", ")))
+	}
+
+	warned := false
+	warn := func(label string, samples pathSamples) {
+		if samples.Count == 0 {
+			return
+		}
+		warned = true
+		lines = append(lines, fmt.Sprintf("  %s%s: %d%s  %s", colorYellow, label, samples.Count, colorReset, strings.Join(samples.Samples, ", ")))
+	}
+	warn("Can't remove", p.Locked)
+	warn("Other owner", p.OtherOwner)
+	warn("Other filesystem", p.OtherFS)
+	warn("Open by a process", p.OpenFiles)
+
+	if !p.OpenCheck {
+		lines = append(lines, fmt.Sprintf("  %sOpen files were not checked on this system%s", colorGray, colorReset))
+	} else if !warned {
+		lines = append(lines, fmt.Sprintf("  %sNothing in the way%s", colorGreen, colorReset))
+	}
+	return lines
+}
+
+// sameTargets reports whether a preview still belongs to the pending delete.
+func sameTargets(a, b []dirEntry) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	pathsA := make([]string, len(a))
+	pathsB := make([]string, len(b))
+	for i := range a {
+		pathsA[i], pathsB[i] = a[i].Path, b[i].Path
+	}
+	sort.Strings(pathsA)
+	sort.Strings(pathsB)
+	for i := range pathsA {
+		if pathsA[i] != pathsB[i] {
+			return false
+		}
+	}
+	return true
+}
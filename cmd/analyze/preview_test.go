@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Whether an entry can be removed depends on its directory, not its mode.
+func TestDeletePreviewLocked(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root may remove anything")
+	}
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"open/readonly.txt":  "r",
+		"sealed/inside.txt":  "i",
+		"sticky/ours.txt":    "o",
+		"open/nested/ok.txt": "k",
+	})
+	if err := os.Chmod(filepath.Join(root, "open/readonly.txt"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "sticky"), 0o777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+	sealed := filepath.Join(root, "sealed")
+	if err := os.Chmod(sealed, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(sealed, 0o755) })
+
+	preview := buildDeletePreview(context.Background(), []dirEntry{{Path: root}})
+	if preview.Locked.Count != 1 {
+		t.Errorf("%d entries can't be removed (%v), want only sealed/inside.txt", preview.Locked.Count, preview.Locked.Samples)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	analyzeConfigFile = "analyze.conf"
+	ignoreFileName    = ".marmotignore"
+)
+
+// ruleAction is what a user rule does to the paths it matches.
+type ruleAction int
+
+const (
+	ruleNone      ruleAction = iota
+	ruleFold                 // Size the directory but don't expand it
+	ruleSkip                 // Leave the path out of the scan entirely
+	ruleNeverFold            // Expand the directory even if a built-in rule folds it
+)
+
+// pathRule is one line of analyze.conf or a .marmotignore file.
+//
+// Patterns follow gitignore: a pattern without a slash matches the name at any
+// depth below base, a pattern with a slash is relative to base, "**" matches
+// any number of directories and a trailing slash only matches directories.
+type pathRule struct {
+	action   ruleAction
+	base     string
+	segments []string
+	anchored bool
+	dirOnly  bool
+}
+
+var ruleKeywords = map[string]ruleAction{
+	"fold":       ruleFold,
+	"skip":       ruleSkip,
+	"never-fold": ruleNeverFold,
+}
+
+var (
+	ruleMu            sync.Mutex
+	globalRules       []pathRule
+	globalRulesLoaded bool
+	dirRuleCache      = make(map[string][]pathRule)
+)
+
+// parseRules reads rules from a config or ignore file. Each line is a pattern
+// optionally preceded by an action:
+//
+//	node_modules/.cache     skip (the gitignore meaning)
+//	skip   *.iso
+//	fold   ~/work/*/out/
+//	never-fold logs/
+//	!logs/                  same as never-fold
+//
+// A # at the start of a line, or after whitespace, starts a comment.
+func parseRules(data, base, home string) []pathRule {
+	var rules []pathRule
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripRuleComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		action := ruleSkip
+		if fields := strings.Fields(line); len(fields) > 1 {
+			if keyword, ok := ruleKeywords[fields[0]]; ok {
+				action = keyword
+				line = strings.TrimSpace(line[len(fields[0]):])
+			}
+		}
+		if strings.HasPrefix(line, "!") {
+			action = ruleNeverFold
+			line = line[1:]
+		}
+
+		rule := pathRule{action: action, base: base}
+		if home != "" && strings.HasPrefix(line, "~/") {
+			rule.base = home
+			line = "/" + line[2:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		rule.anchored = strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		rule.segments = strings.Split(line, "/")
+		valid := true
+		for _, segment := range rule.segments {
+			if _, err := filepath.Match(segment, ""); err != nil {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// stripRuleComment cuts a trailing "# ..." comment off a rule line. A # inside
+// a name, as in "issue#12", is kept.
+func stripRuleComment(line string) string {
+	for i, r := range line {
+		if r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func (r pathRule) matches(path string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(r.base, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	if !r.anchored {
+		ok, _ := filepath.Match(r.segments[0], filepath.Base(path))
+		return ok
+	}
+	return matchSegments(r.segments, strings.Split(rel, string(filepath.Separator)))
+}
+
+// matchSegments matches path components against pattern components, letting
+// "**" stand for zero or more components.
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(parts); skip++ {
+				if matchSegments(pattern[1:], parts[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// evalRules returns the action of the last rule matching path, so later
+// layers (and later lines) override earlier ones.
+func evalRules(rules []pathRule, path string, isDir bool) ruleAction {
+	action := ruleNone
+	for _, rule := range rules {
+		if rule.matches(path, isDir) {
+			action = rule.action
+		}
+	}
+	return action
+}
+
+func getAnalyzeConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "marmot", analyzeConfigFile), nil
+}
+
+func loadGlobalRulesLocked() []pathRule {
+	if globalRulesLoaded {
+		return globalRules
+	}
+	globalRulesLoaded = true
+	configPath, err := getAnalyzeConfigPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	home, _ := os.UserHomeDir()
+	globalRules = parseRules(string(data), "/", home)
+	return globalRules
+}
+
+// withIgnoreFile returns rules extended by dir/.marmotignore, if present.
+// The parent slice is never modified.
+func withIgnoreFile(rules []pathRule, dir string) []pathRule {
+	data, err := os.ReadFile(filepath.Join(dir, ignoreFileName))
+	if err != nil {
+		return rules
+	}
+	local := parseRules(string(data), dir, "")
+	if len(local) == 0 {
+		return rules
+	}
+	merged := make([]pathRule, 0, len(rules)+len(local))
+	merged = append(merged, rules...)
+	return append(merged, local...)
+}
+
+// rulesForDir returns the rules that apply to the children of dir: the
+// global config followed by every .marmotignore from / down to dir.
+func rulesForDir(dir string) []pathRule {
+	dir = filepath.Clean(dir)
+	ruleMu.Lock()
+	defer ruleMu.Unlock()
+	return rulesForDirLocked(dir)
+}
+
+func rulesForDirLocked(dir string) []pathRule {
+	if rules, ok := dirRuleCache[dir]; ok {
+		return rules
+	}
+	var rules []pathRule
+	if parent := filepath.Dir(dir); parent == dir {
+		rules = loadGlobalRulesLocked()
+	} else {
+		rules = rulesForDirLocked(parent)
+	}
+	rules = withIgnoreFile(rules, dir)
+	dirRuleCache[dir] = rules
+	return rules
+}
+
+// childRules is used while scanning: the directory listing already tells
+// whether a .marmotignore exists, so no extra stat is needed.
+func childRules(rules []pathRule, dir string, children []os.DirEntry) []pathRule {
+	for _, child := range children {
+		if child.Name() == ignoreFileName && child.Type().IsRegular() {
+			return withIgnoreFile(rules, dir)
+		}
+	}
+	return rules
+}
+
+// ruleActionFor evaluates the user rules for path outside of a scan.
+func ruleActionFor(path string, isDir bool) ruleAction {
+	return evalRules(rulesForDir(filepath.Dir(path)), path, isDir)
+}
+
+// resetRules drops the loaded rules so edits to analyze.conf and
+// .marmotignore files are picked up by the next scan.
+func resetRules() {
+	ruleMu.Lock()
+	defer ruleMu.Unlock()
+	globalRules = nil
+	globalRulesLoaded = false
+	dirRuleCache = make(map[string][]pathRule)
+}
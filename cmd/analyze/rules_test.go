@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The example from the README must work as documented.
+func TestParseRulesReadmeExample(t *testing.T) {
+	const conf = `# ~/.config/marmot/analyze.conf
+never-fold logs/          # my logs are real data, show what's inside
+fold ~/work/*/out/        # build output in every work project
+skip *.iso
+`
+	rules := parseRules(conf, "/", "/home/u")
+	if len(rules) != 3 {
+		t.Fatalf("parsed %d rules, want 3", len(rules))
+	}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  ruleAction
+	}{
+		{"/home/u/logs", true, ruleNeverFold},
+		{"/home/u/logs", false, ruleNone},
+		{"/home/u/work/a/out", true, ruleFold},
+		{"/home/u/work/a/b/out", true, ruleNone},
+		{"/home/u/Downloads/disk.iso", false, ruleSkip},
+		{"/home/u/notes#1", false, ruleNone},
+	}
+	for _, tt := range tests {
+		if got := evalRules(rules, tt.path, tt.isDir); got != tt.want {
+			t.Errorf("evalRules(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestStripRuleComment(t *testing.T) {
+	tests := map[string]string{
+		"# comment":          "",
+		"logs/  # real data": "logs/  ",
+		"skip\t# tab":        "skip\t",
+		"issue#12":           "issue#12",
+	}
+	for line, want := range tests {
+		if got := stripRuleComment(line); got != want {
+			t.Errorf("stripRuleComment(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+// A .marmotignore applies below its directory, and a deeper one overrides it.
+func TestNestedIgnoreRules(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".marmotignore":     "*.iso\nfold data/\n",
+		"sub/.marmotignore": "!data/\nskip notes.txt\n",
+		"sub/data/":         "",
+		"data/":             "",
+	})
+	t.Setenv("HOME", t.TempDir())
+	resetRules()
+	t.Cleanup(resetRules)
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  ruleAction
+	}{
+		{"a.iso", false, ruleSkip},
+		{"sub/b.iso", false, ruleSkip},
+		{"data", true, ruleFold},
+		{"sub/data", true, ruleNeverFold},
+		{"notes.txt", false, ruleNone},
+		{"sub/notes.txt", false, ruleSkip},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(root, tt.path)
+		if got := ruleActionFor(path, tt.isDir); got != tt.want {
+			t.Errorf("ruleActionFor(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+// A negated pattern expands a directory the built-in list folds.
+func TestNegatedRuleExpandsFoldedDir(t *testing.T) {
+	// Large enough that compacting the tree doesn't drop the listings
+	big := strings.Repeat("x", treeCollapseSize)
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		".marmotignore":         "!node_modules/\n",
+		"node_modules/a/a.js":   big,
+		"vendor/b/b.go":         big,
+		"src/node_modules/c.js": big,
+	})
+	result := scanFixture(t, root)
+
+	for _, name := range []string{"node_modules", "src/node_modules"} {
+		if node := result.Tree.find(filepath.Join(root, name)); node == nil || node.Collapsed {
+			t.Errorf("%s was folded: %+v", name, node)
+		}
+	}
+	if node := result.Tree.find(filepath.Join(root, "vendor")); node == nil || !node.Collapsed {
+		t.Errorf("vendor was not folded: %+v", node)
+	}
+}
+
+// Folded directories are listed in batches; the ignore file must apply to
+// every entry, not just those read after it.
+func TestIgnoreFileAppliesToWholeListing(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "node_modules")
+	writeTree(t, root, map[string]string{"node_modules/.marmotignore": "*.bin\n"})
+	for i := 0; i < 3*sizerReadBatch; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%d.bin", i)), []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("HOME", t.TempDir())
+	resetRules()
+	t.Cleanup(resetRules)
+
+	state := &scanState{links: newHardlinkSet()}
+	sized, err := sizeDir(context.Background(), dir, state, rulesForDir(root), sizeProgress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sized.Files != 1 {
+		t.Errorf("sized %d files, want only the ignore file", sized.Files)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const whitelistFile = "whitelist"
+
+// protectedRoots can never be deleted themselves. Mirrors the critical system
+// directories in validate_path_for_deletion (lib/core/file_ops.sh).
+var protectedRoots = map[string]bool{
+	"/":                   true,
+	"/bin":                true,
+	"/sbin":               true,
+	"/usr":                true,
+	"/usr/bin":            true,
+	"/usr/sbin":           true,
+	"/usr/lib":            true,
+	"/usr/local":          true,
+	"/etc":                true,
+	"/var":                true,
+	"/boot":               true,
+	"/lib":                true,
+	"/lib64":              true,
+	"/opt":                true,
+	"/run":                true,
+	"/tmp":                true,
+	"/root":               true,
+	"/home":               true,
+	"/Users":              true,
+	"/System":             true,
+	"/Library":            true,
+	"/Library/Extensions": true,
+	"/Applications":       true,
+	"/private":            true,
+}
+
+// protectedTrees are protected together with everything below them.
+var protectedTrees = []string{"/System", "/proc", "/sys", "/dev"}
+
+// rootProtectedTrees are additionally off limits when running as root, where a
+// stray delete can take the system down.
+var rootProtectedTrees = []string{"/bin", "/sbin", "/usr", "/etc", "/lib", "/lib64", "/boot"}
+
+// defaultWhitelistPatterns match DEFAULT_WHITELIST_PATTERNS in lib/core/base.sh
+// and apply when the user has no whitelist file.
+var defaultWhitelistPatterns = []string{
+	"~/Library/Caches/ms-playwright*",
+	"~/.cache/huggingface*",
+	"~/.m2/repository/*",
+	"~/.ollama/models/*",
+	"~/.cache/nssurge/*",
+	"~/.config/nssurge/*",
+	"~/.cache/R/renv/*",
+}
+
+// validatePathForDeletion returns an error explaining why path must not be
+// deleted, or nil. Every delete issued by the analyzer goes through it.
+func validatePathForDeletion(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty path")
+	}
+	for _, r := range path {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("path contains control characters")
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("path must be absolute")
+	}
+	for _, part := range strings.Split(path, "/") {
+		if part == ".." {
+			return fmt.Errorf("path traversal not allowed")
+		}
+	}
+	if filepath.Clean(path) != path {
+		return fmt.Errorf("path is not in canonical form")
+	}
+
+	if protectedRoots[path] {
+		return fmt.Errorf("%s is a protected system directory", path)
+	}
+	trees := protectedTrees
+	if os.Geteuid() == 0 {
+		trees = append(append([]string{}, trees...), rootProtectedTrees...)
+	}
+	for _, tree := range trees {
+		if isSameOrBelow(path, tree) {
+			return fmt.Errorf("%s is inside protected %s", displayPath(path), tree)
+		}
+	}
+
+	if home, err := os.UserHomeDir(); err == nil && home != "" && isSameOrBelow(home, path) {
+		return fmt.Errorf("%s contains your home directory", displayPath(path))
+	}
+
+	for _, pattern := range loadWhitelistPatterns() {
+		if whitelistMatch(pattern, path) {
+			return fmt.Errorf("%s is whitelisted (%s)", displayPath(path), displayPath(pattern))
+		}
+		// Deleting a parent would take the whitelisted items with it
+		if matches, _ := filepath.Glob(pattern); len(matches) > 0 {
+			for _, match := range matches {
+				if match != path && isSameOrBelow(match, path) {
+					return fmt.Errorf("%s contains whitelisted %s", displayPath(path), displayPath(match))
+				}
+			}
+		}
+	}
+
+	// A symlinked directory above path would make the delete land elsewhere;
+	// path itself is removed as a link and needs no resolving
+	if parent, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil && parent != filepath.Dir(path) {
+		if err := validatePathForDeletion(filepath.Join(parent, filepath.Base(path))); err != nil {
+			return fmt.Errorf("%s is reached through a symlink: %v", displayPath(path), err)
+		}
+	}
+	return nil
+}
+
+// isSameOrBelow reports whether path is dir or inside it.
+func isSameOrBelow(path, dir string) bool {
+	if path == dir {
+		return true
+	}
+	if dir == "/" {
+		return strings.HasPrefix(path, "/")
+	}
+	return strings.HasPrefix(path, dir+"/")
+}
+
+func getWhitelistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "marmot", whitelistFile), nil
+}
+
+// loadWhitelistPatterns reads ~/.config/marmot/whitelist, the file managed by
+// `marmot clean --whitelist`. Patterns come back with ~ expanded.
+func loadWhitelistPatterns() []string {
+	home, _ := os.UserHomeDir()
+	expand := func(pattern string) string {
+		if home != "" && (pattern == "~" || strings.HasPrefix(pattern, "~/")) {
+			return home + pattern[1:]
+		}
+		return pattern
+	}
+
+	var patterns []string
+	var file *os.File
+	whitelistPath, err := getWhitelistPath()
+	if err == nil {
+		file, err = os.Open(whitelistPath)
+	}
+	if err != nil {
+		for _, pattern := range defaultWhitelistPatterns {
+			patterns = append(patterns, expand(pattern))
+		}
+		return patterns
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = expand(line)
+		// Sentinels such as FINDER_METADATA are not paths
+		if !strings.HasPrefix(line, "/") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	return patterns
+}
+
+// whitelistMatch uses the shell's [[ $path == $pattern ]] semantics, where *
+// also matches across slashes.
+func whitelistMatch(pattern, path string) bool {
+	if pattern == path {
+		return true
+	}
+	if !strings.ContainsAny(pattern, "*?") {
+		return false
+	}
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	re, err := regexp.Compile(expr.String())
+	return err == nil && re.MatchString(path)
+}
+
+// partitionDeleteTargets splits targets into those that may be deleted and
+// refusals carrying the reason, so the UI can explain them before confirming.
+func partitionDeleteTargets(targets []dirEntry) ([]dirEntry, []deleteItemResult) {
+	var allowed []dirEntry
+	var refused []deleteItemResult
+	for _, target := range targets {
+		if err := validatePathForDeletion(target.Path); err != nil {
+			refused = append(refused, deleteItemResult{path: target.Path, err: err})
+			continue
+		}
+		allowed = append(allowed, target)
+	}
+	return allowed, refused
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// withWhitelist points HOME at a temporary directory holding whitelist as
+// ~/.config/marmot/whitelist, or no whitelist file when it is empty.
+func withWhitelist(t *testing.T, whitelist string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if whitelist != "" {
+		writeTree(t, home, map[string]string{".config/marmot/whitelist": whitelist})
+	}
+	return home
+}
+
+func TestValidatePathForDeletion(t *testing.T) {
+	home := withWhitelist(t, "# nothing\n")
+	isRoot := os.Geteuid() == 0
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"", false},
+		{"relative/path", false},
+		{"/tmp/a/../b", false},
+		{"/tmp//a", false},
+		{"/tmp/a\nb", false},
+		{"/", false},
+		{"/usr", false},
+		{"/etc", false},
+		{"/tmp", false},
+		{"/Applications", false},
+		{"/System/Library/Fonts", false},
+		{"/proc/1", false},
+		{"/sys/kernel", false},
+		{"/dev/null", false},
+		{"/usr/bin/ls", !isRoot},
+		{"/etc/hosts", !isRoot},
+		{filepath.Dir(home), false},
+		{home, false},
+		{filepath.Join(home, "Downloads"), true},
+		{"/tmp/marmot-test", true},
+		{"/opt/app/cache", true},
+	}
+	for _, tt := range tests {
+		err := validatePathForDeletion(tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("validatePathForDeletion(%q) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
+
+func TestWhitelistMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/home/u/.m2/repository/*", "/home/u/.m2/repository/org", true},
+		{"/home/u/.m2/repository/*", "/home/u/.m2/repository/org/junit/a.jar", true},
+		{"/home/u/.m2/repository/*", "/home/u/.m2/repository", false},
+		{"/home/u/.cache/huggingface*", "/home/u/.cache/huggingface", true},
+		{"/home/u/.cache/huggingface*", "/home/u/.cache/huggingface/hub", true},
+		{"/home/u/.cache/huggingface*", "/home/u/.cache/hugging", false},
+		{"/home/u/data?", "/home/u/data1", true},
+		{"/home/u/data?", "/home/u/data12", false},
+		{"/home/u/a.b", "/home/u/a.b", true},
+		{"/home/u/a.b", "/home/u/axb", false},
+		{"/home/u/keep", "/home/u/keep/file", false},
+	}
+	for _, tt := range tests {
+		if got := whitelistMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("whitelistMatch(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestLoadWhitelistPatterns(t *testing.T) {
+	home := withWhitelist(t, "# comment\n\n  ~/keep/*  \nFINDER_METADATA\n/srv/data\n~\n")
+	want := []string{filepath.Join(home, "keep") + "/*", "/srv/data", home}
+	if got := loadWhitelistPatterns(); !reflect.DeepEqual(got, want) {
+		t.Errorf("loadWhitelistPatterns() = %q, want %q", got, want)
+	}
+
+	// Without a whitelist file the defaults apply
+	home = withWhitelist(t, "")
+	got := loadWhitelistPatterns()
+	if len(got) != len(defaultWhitelistPatterns) {
+		t.Fatalf("loaded %d default patterns, want %d", len(got), len(defaultWhitelistPatterns))
+	}
+	for i, pattern := range got {
+		if want := home + defaultWhitelistPatterns[i][1:]; pattern != want {
+			t.Errorf("default pattern %d = %q, want %q", i, pattern, want)
+		}
+	}
+}
+
+func TestValidatePathForDeletionWhitelist(t *testing.T) {
+	home := withWhitelist(t, "~/keep/*\n~/models\n")
+	writeTree(t, home, map[string]string{
+		"keep/a":            "a",
+		"work/models/":      "",
+		"projects/models/x": "x",
+	})
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{filepath.Join(home, "keep/a"), false},
+		{filepath.Join(home, "keep/b/c"), false},
+		{filepath.Join(home, "keep"), false}, // Contains keep/a
+		{filepath.Join(home, "models"), false},
+		{filepath.Join(home, "work/models"), true},
+		{filepath.Join(home, "projects"), true},
+	}
+	for _, tt := range tests {
+		err := validatePathForDeletion(tt.path)
+		if (err == nil) != tt.ok {
+			t.Errorf("validatePathForDeletion(%q) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
+
+// A path that goes through a symlink is checked where it really is.
+func TestValidatePathForDeletionSymlinks(t *testing.T) {
+	home := withWhitelist(t, "~/keep/*\n")
+	writeTree(t, home, map[string]string{"keep/a": "a", "data/b": "b"})
+	links := map[string]string{
+		"root":  "/",
+		"proc":  "/proc",
+		"kept":  filepath.Join(home, "keep"),
+		"data2": filepath.Join(home, "data"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(home, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{"root/usr", false},
+		{"root/etc", false},
+		{"proc/self", false},
+		{"kept/a", false},
+		{"data2/b", true},
+		// The link itself is removed, not what it points to
+		{"root", true},
+		{"proc", true},
+		{"kept", true},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(home, tt.path)
+		err := validatePathForDeletion(path)
+		if (err == nil) != tt.ok {
+			t.Errorf("validatePathForDeletion(%q) = %v, want ok=%v", tt.path, err, tt.ok)
+		}
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,16 +21,45 @@ import (
 
 var scanGroup singleflight.Group
 
-func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) (scanResult, error) {
+// duRuns tracks running du processes so quitting can wait for them to be killed.
+var duRuns sync.WaitGroup
+
+// scanState is shared by every directory of one scan.
+type scanState struct {
+	opts  scanOptions
+	links *hardlinkSet
+
+	mu         sync.Mutex
+	folded     []string // Directories sized without being expanded
+	readErrors int64    // Directories that could not be listed
+	unreadable []string // The first maxUnreadableListed of them
+}
+
+func (s *scanState) addFolded(path string) {
+	s.mu.Lock()
+	s.folded = append(s.folded, path)
+	s.mu.Unlock()
+}
+
+// addUnreadable records a directory left out of the sizes because it could
+// not be listed, as du reports them.
+func (s *scanState) addUnreadable(path string) {
+	s.mu.Lock()
+	s.readErrors++
+	if len(s.unreadable) < maxUnreadableListed {
+		s.unreadable = append(s.unreadable, path)
+	}
+	s.mu.Unlock()
+}
+
+// scanPathConcurrent scans root until done or until ctx is cancelled, in
+// which case workers stop early, du processes are killed and ctx.Err() is returned.
+func scanPathConcurrent(ctx context.Context, root string, opts scanOptions, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) (scanResult, error) {
 	children, err := os.ReadDir(root)
 	if err != nil {
 		return scanResult{}, err
 	}
 
-	var total int64
-	entries := make([]dirEntry, 0, len(children))
-	largeFiles := make([]fileEntry, 0, maxLargeFiles*2)
-
 	// Use worker pool for concurrent directory scanning
 	// For I/O-bound operations, use more workers than CPU count
 	numWorkers := runtime.NumCPU() * cpuMultiplier
@@ -48,63 +76,126 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 		numWorkers = 1
 	}
 	sem := make(chan struct{}, numWorkers)
-	var wg sync.WaitGroup
 
-	// Use channels to collect results without lock contention
-	entryChan := make(chan dirEntry, len(children))
-	largeFileChan := make(chan fileEntry, maxLargeFiles*2)
+	// The root keeps its full listing; only nested subtrees are compacted.
+	tree := &dirNode{Name: filepath.Base(root), Path: root, IsDir: true, ScannedAt: time.Now()}
+	state := &scanState{opts: opts, links: newHardlinkSet()}
+	fillDirNode(ctx, tree, children, sem, state, rulesForDir(root), filesScanned, dirsScanned, bytesScanned, currentPath)
+	if err := ctx.Err(); err != nil {
+		return scanResult{}, err
+	}
 
-	// Start goroutines to collect from channels
-	var collectorWg sync.WaitGroup
-	collectorWg.Add(2)
-	go func() {
-		defer collectorWg.Done()
-		for entry := range entryChan {
-			entries = append(entries, entry)
-		}
-	}()
-	go func() {
-		defer collectorWg.Done()
-		for file := range largeFileChan {
-			largeFiles = append(largeFiles, file)
-		}
-	}()
+	// Folded directories are only sized, so their large files are looked up
+	// in an index (Spotlight, plocate, ...) instead. Failures are silent: the
+	// scan results are complete on their own.
+	if indexed, info := findIndexedLargeFiles(ctx, root, minLargeFileSize, opts, state.folded); info.Backend != "" {
+		tree.addLargeFiles(indexed)
+		tree.LargeIndex = info
+	}
+
+	result := tree.result()
+	result.ReadErrors = state.readErrors
+	result.Unreadable = state.unreadable
+	return result, nil
+}
+
+// scanDirTree recursively scans a directory below the scan root and returns its
+// compacted node.
+func scanDirTree(ctx context.Context, root, name string, state *scanState, rules []pathRule, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) *dirNode {
+	node := &dirNode{Name: name, Path: root, IsDir: true}
+	if ctx.Err() != nil {
+		node.Collapsed = true
+		return node
+	}
+
+	// Read immediate children
+	children, err := os.ReadDir(root)
+	if err != nil {
+		state.addUnreadable(root)
+		node.Collapsed = true
+		return node
+	}
+	rules = childRules(rules, root, children)
+
+	// Limit concurrent subdirectory scans to avoid too many goroutines
+	maxConcurrent := runtime.NumCPU() * 2
+	if maxConcurrent > maxDirWorkers {
+		maxConcurrent = maxDirWorkers
+	}
+	sem := make(chan struct{}, maxConcurrent)
+
+	fillDirNode(ctx, node, children, sem, state, rules, filesScanned, dirsScanned, bytesScanned, currentPath)
+	node.compact()
+	return node
+}
 
-	isRootDir := root == "/"
+// fillDirNode scans the given children of node in parallel (bounded by sem)
+// and attaches the resulting nodes to it. Repeated hardlinks are counted once
+// across the whole scan, and mount points are labeled (or skipped) per state.opts.
+// rules are the user rules that apply to the children.
+func fillDirNode(ctx context.Context, node *dirNode, children []fs.DirEntry, sem chan struct{}, state *scanState, rules []pathRule, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) {
+	isRootDir := node.Path == "/"
 
 	// Additional Linux system directories to skip in root
 	linuxSystemDirs := map[string]bool{
-		"proc": true,  // /proc virtual filesystem
-		"sys":  true,  // /sys virtual filesystem
-		"dev":  true,  // /dev device files
-		"run":  true,  // /run runtime data
+		"proc": true, // /proc virtual filesystem
+		"sys":  true, // /sys virtual filesystem
+		"dev":  true, // /dev device files
+		"run":  true, // /run runtime data
 	}
 
-	for _, child := range children {
-		fullPath := filepath.Join(root, child.Name())
+	// Children on another device than node are mount points
+	var parentDev uint64
+	hasParentDev := false
+	if info, err := os.Lstat(node.Path); err == nil {
+		parentDev, hasParentDev = deviceOf(info)
+	}
+
+	progress := sizeProgress{files: filesScanned, dirs: dirsScanned, bytes: bytesScanned, current: currentPath}
+
+	// Each child writes only its own slot, so no locking is needed
+	slots := make([]*dirNode, len(children))
+	var wg sync.WaitGroup
+
+	for i, child := range children {
+		// Stop handing out work once the scan is cancelled
+		if ctx.Err() != nil {
+			break
+		}
+		fullPath := filepath.Join(node.Path, child.Name())
 
 		// Skip Linux virtual filesystem directories
 		if isRootDir && runtime.GOOS != "darwin" && linuxSystemDirs[child.Name()] {
 			continue
 		}
 
+		// Paths skipped by analyze.conf or a .marmotignore
+		action := evalRules(rules, fullPath, child.IsDir())
+		if action == ruleSkip {
+			continue
+		}
+
 		// Skip symlinks to avoid following them into unexpected locations
 		// Use Type() instead of IsDir() to check without following symlinks
 		if child.Type()&fs.ModeSymlink != 0 {
-			// For symlinks, get their target info but mark them specially
+			// For symlinks, just count their size without following
 			info, err := child.Info()
 			if err != nil {
 				continue
 			}
 			size := getActualFileSize(fullPath, info)
-			atomic.AddInt64(&total, size)
+			atomic.AddInt64(filesScanned, 1)
+			atomic.AddInt64(bytesScanned, size)
 
-			entryChan <- dirEntry{
+			slots[i] = &dirNode{
 				Name:       child.Name() + " →", // Add arrow to indicate symlink
 				Path:       fullPath,
 				Size:       size,
 				IsDir:      false, // Don't allow navigation into symlinks
+				IsSymlink:  true,
+				FileCount:  1,
 				LastAccess: getLastAccessTimeFromInfo(info),
+				ModTime:    info.ModTime(),
 			}
 			continue
 		}
@@ -115,53 +206,96 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 				continue
 			}
 
+			// A directory's own atime is bumped by scanning it, so only its mtime is kept
+			var dirModTime time.Time
+			isMount := false
+			if info, err := child.Info(); err == nil {
+				dirModTime = info.ModTime()
+				if dev, ok := deviceOf(info); ok && hasParentDev && dev != parentDev {
+					isMount = true
+				}
+			}
+
+			var mountFS string
+			if isMount {
+				mountFS = mountFSType(fullPath)
+				if reason := state.opts.mountSkipReason(mountFS); reason != "" {
+					slots[i] = &dirNode{
+						Name:      child.Name(),
+						Path:      fullPath,
+						IsDir:     true,
+						ModTime:   dirModTime,
+						Collapsed: true,
+						IsMount:   true,
+						MountFS:   mountFS,
+						MountSkip: reason,
+					}
+					continue
+				}
+			}
+
 			// For folded directories, calculate size quickly without expanding
-			if shouldFoldDirWithPath(child.Name(), fullPath) {
+			if shouldFoldDirWithPath(child.Name(), fullPath, action) {
+				state.addFolded(fullPath)
 				wg.Add(1)
-				go func(name, path string) {
+				go func(i int, name, path string, modTime time.Time) {
 					defer wg.Done()
 					sem <- struct{}{}
 					defer func() { <-sem }()
+					if ctx.Err() != nil {
+						return
+					}
 
-					// Try du command first for folded dirs (much faster)
-					size, err := getDirectorySizeFromDu(path)
-					if err != nil || size <= 0 {
-						// Fallback to walk if du fails
-						size = calculateDirSizeFast(path, filesScanned, dirsScanned, bytesScanned, currentPath)
+					// Unreadable subdirectories are left out, like du does
+					sized, err := sizeDir(ctx, path, state, rules, progress)
+					if err != nil && ctx.Err() == nil {
+						state.addUnreadable(path)
 					}
-					atomic.AddInt64(&total, size)
+					crossCheckWithDu(ctx, path, sized.Size)
 					atomic.AddInt64(dirsScanned, 1)
 
-					entryChan <- dirEntry{
+					if sized.ModTime.After(modTime) {
+						modTime = sized.ModTime
+					}
+					slots[i] = &dirNode{
 						Name:       name,
 						Path:       path,
-						Size:       size,
+						Size:       sized.Size,
 						IsDir:      true,
-						LastAccess: time.Time{}, // Lazy load when displayed
+						LastAccess: sized.LastAccess,
+						ModTime:    modTime,
+						FileCount:  sized.Files,
+						DirCount:   sized.Dirs,
+						Deduped:    sized.Deduped,
+						Collapsed:  true,
+						IsMount:    isMount,
+						MountFS:    mountFS,
+						Types:      sized.Types.totals(),
+						Ages:       sized.Ages,
 					}
-				}(child.Name(), fullPath)
+				}(i, child.Name(), fullPath, dirModTime)
 				continue
 			}
 
-			// Normal directory: full scan with detail
+			// Normal directory: recursive scan with detail
 			wg.Add(1)
-			go func(name, path string) {
+			go func(i int, name, path string, modTime time.Time) {
 				defer wg.Done()
 				sem <- struct{}{}
 				defer func() { <-sem }()
+				if ctx.Err() != nil {
+					return
+				}
 
-				size := calculateDirSizeConcurrent(path, largeFileChan, filesScanned, dirsScanned, bytesScanned, currentPath)
-				atomic.AddInt64(&total, size)
-				atomic.AddInt64(dirsScanned, 1)
-
-				entryChan <- dirEntry{
-					Name:       name,
-					Path:       path,
-					Size:       size,
-					IsDir:      true,
-					LastAccess: time.Time{}, // Lazy load when displayed
+				node := scanDirTree(ctx, path, name, state, rules, filesScanned, dirsScanned, bytesScanned, currentPath)
+				if modTime.After(node.ModTime) {
+					node.ModTime = modTime
 				}
-			}(child.Name(), fullPath)
+				node.IsMount = isMount
+				node.MountFS = mountFS
+				slots[i] = node
+				atomic.AddInt64(dirsScanned, 1)
+			}(i, child.Name(), fullPath, dirModTime)
 			continue
 		}
 
@@ -170,65 +304,45 @@ func scanPathConcurrent(root string, filesScanned, dirsScanned, bytesScanned *in
 			continue
 		}
 		// Get actual disk usage for sparse files and cloud files
-		size := getActualFileSize(fullPath, info)
-		atomic.AddInt64(&total, size)
+		fullSize := getActualFileSize(fullPath, info)
+		size := state.links.countedSize(info, fullSize)
 		atomic.AddInt64(filesScanned, 1)
 		atomic.AddInt64(bytesScanned, size)
 
-		entryChan <- dirEntry{
+		slots[i] = &dirNode{
 			Name:       child.Name(),
 			Path:       fullPath,
 			Size:       size,
 			IsDir:      false,
 			LastAccess: getLastAccessTimeFromInfo(info),
+			ModTime:    info.ModTime(),
+			FileCount:  1,
+			Deduped:    fullSize - size,
 		}
-		// Only track large files that are not code/text files
-		if !shouldSkipFileForLargeTracking(fullPath) && size >= minLargeFileSize {
-			largeFileChan <- fileEntry{Name: child.Name(), Path: fullPath, Size: size}
+
+		// Update current path
+		if currentPath != nil {
+			*currentPath = fullPath
 		}
 	}
 
 	wg.Wait()
+	node.setChildren(slots)
+}
 
-	// Close channels and wait for collectors to finish
-	close(entryChan)
-	close(largeFileChan)
-	collectorWg.Wait()
-
-	sort.Slice(entries, func(i, j int) bool {
-		return entries[i].Size > entries[j].Size
-	})
-	if len(entries) > maxEntries {
-		entries = entries[:maxEntries]
-	}
-
-	// Try to use Spotlight (mdfind) for faster large file discovery
-	// This is a performance optimization that gracefully falls back to scan results
-	// if Spotlight is unavailable or fails. The fallback is intentionally silent
-	// because users only care about correct results, not the method used.
-	if spotlightFiles := findLargeFilesWithSpotlight(root, minLargeFileSize); len(spotlightFiles) > 0 {
-		largeFiles = spotlightFiles
-	} else {
-		// Use files collected during scanning (fallback path)
-		// Sort and trim large files collected from scanning
-		sort.Slice(largeFiles, func(i, j int) bool {
-			return largeFiles[i].Size > largeFiles[j].Size
-		})
-		if len(largeFiles) > maxLargeFiles {
-			largeFiles = largeFiles[:maxLargeFiles]
-		}
+// shouldFoldDirWithPath reports whether a directory is sized without being
+// expanded. The user rule action (from analyze.conf and .marmotignore files)
+// wins over the built-in lists.
+func shouldFoldDirWithPath(name, path string, action ruleAction) bool {
+	switch action {
+	case ruleFold:
+		return true
+	case ruleNeverFold:
+		return false
 	}
 
-	return scanResult{
-		Entries:    entries,
-		LargeFiles: largeFiles,
-		TotalSize:  total,
-	}, nil
-}
-
-func shouldFoldDirWithPath(name, path string) bool {
 	// Check basic fold list first
-	if foldDirs[name] {
+	if isBuiltinFoldName(name) {
 		return true
 	}
 
@@ -250,250 +364,26 @@ func shouldFoldDirWithPath(name, path string) bool {
 	return false
 }
 
-func shouldSkipFileForLargeTracking(path string) bool {
-	ext := strings.ToLower(filepath.Ext(path))
-	return skipExtensions[ext]
-}
-
-// calculateDirSizeFast performs fast directory size calculation without detailed tracking or large file detection.
-// Updates progress counters in batches to reduce atomic operation overhead.
-func calculateDirSizeFast(root string, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) int64 {
-	var total int64
-	var localFiles, localDirs int64
-	var batchBytes int64
-
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	walkFunc := func(path string, d fs.DirEntry, err error) error {
-		// Check for timeout
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
-		if err != nil {
-			return nil
-		}
-		if d.IsDir() {
-			localDirs++
-			// Batch update every N dirs to reduce atomic operations
-			if localDirs%batchUpdateSize == 0 {
-				atomic.AddInt64(dirsScanned, batchUpdateSize)
-				localDirs = 0
-			}
-			return nil
-		}
-		info, err := d.Info()
-		if err != nil {
-			return nil
-		}
-		// Get actual disk usage for sparse files and cloud files
-		size := getActualFileSize(path, info)
-		total += size
-		batchBytes += size
-		localFiles++
-		if currentPath != nil {
-			*currentPath = path
-		}
-		// Batch update every N files to reduce atomic operations
-		if localFiles%batchUpdateSize == 0 {
-			atomic.AddInt64(filesScanned, batchUpdateSize)
-			atomic.AddInt64(bytesScanned, batchBytes)
-			localFiles = 0
-			batchBytes = 0
-		}
-		return nil
-	}
-
-	_ = filepath.WalkDir(root, walkFunc)
-
-	// Final update for remaining counts
-	if localFiles > 0 {
-		atomic.AddInt64(filesScanned, localFiles)
-	}
-	if localDirs > 0 {
-		atomic.AddInt64(dirsScanned, localDirs)
-	}
-	if batchBytes > 0 {
-		atomic.AddInt64(bytesScanned, batchBytes)
-	}
-
-	return total
-}
-
-// Use Spotlight (mdfind) to quickly find large files in a directory
-func findLargeFilesWithSpotlight(root string, minSize int64) []fileEntry {
-	// mdfind query: files >= minSize in the specified directory
-	query := fmt.Sprintf("kMDItemFSSize >= %d", minSize)
-
-	ctx, cancel := context.WithTimeout(context.Background(), mdlsTimeout)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "mdfind", "-onlyin", root, query)
-	output, err := cmd.Output()
-	if err != nil {
-		// Fallback: mdfind not available or failed
-		return nil
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	var files []fileEntry
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		// Filter out code files first (cheapest check, no I/O)
-		if shouldSkipFileForLargeTracking(line) {
-			continue
-		}
-
-		// Filter out files in folded directories (cheap string check)
-		if isInFoldedDir(line) {
-			continue
-		}
-
-		// Use Lstat instead of Stat (faster, doesn't follow symlinks)
-		info, err := os.Lstat(line)
-		if err != nil {
-			continue
-		}
-
-		// Skip if it's a directory or symlink
-		if info.IsDir() || info.Mode()&os.ModeSymlink != 0 {
-			continue
-		}
-
-		// Get actual disk usage for sparse files and cloud files
-		actualSize := getActualFileSize(line, info)
-		files = append(files, fileEntry{
-			Name: filepath.Base(line),
-			Path: line,
-			Size: actualSize,
-		})
-	}
-
-	// Sort by size (descending)
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].Size > files[j].Size
-	})
-
-	// Return top N
-	if len(files) > maxLargeFiles {
-		files = files[:maxLargeFiles]
+func isBuiltinFoldName(name string) bool {
+	if foldDirs[name] {
+		return true
 	}
-
-	return files
-}
-
-// isInFoldedDir checks if a path is inside a folded directory (optimized)
-func isInFoldedDir(path string) bool {
-	// Split path into components for faster checking
-	parts := strings.Split(path, string(os.PathSeparator))
-	for _, part := range parts {
-		if foldDirs[part] {
+	for _, pattern := range foldDirPatterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
 			return true
 		}
 	}
 	return false
 }
 
-func calculateDirSizeConcurrent(root string, largeFileChan chan<- fileEntry, filesScanned, dirsScanned, bytesScanned *int64, currentPath *string) int64 {
-	// Read immediate children
-	children, err := os.ReadDir(root)
-	if err != nil {
-		return 0
-	}
-
-	var total int64
-	var wg sync.WaitGroup
-
-	// Limit concurrent subdirectory scans to avoid too many goroutines
-	maxConcurrent := runtime.NumCPU() * 2
-	if maxConcurrent > maxDirWorkers {
-		maxConcurrent = maxDirWorkers
-	}
-	sem := make(chan struct{}, maxConcurrent)
-
-	for _, child := range children {
-		fullPath := filepath.Join(root, child.Name())
-
-		// Skip symlinks to avoid following them into unexpected locations
-		if child.Type()&fs.ModeSymlink != 0 {
-			// For symlinks, just count their size without following
-			info, err := child.Info()
-			if err != nil {
-				continue
-			}
-			size := getActualFileSize(fullPath, info)
-			total += size
-			atomic.AddInt64(filesScanned, 1)
-			atomic.AddInt64(bytesScanned, size)
-			continue
-		}
-
-		if child.IsDir() {
-			// Check if this is a folded directory
-			if shouldFoldDirWithPath(child.Name(), fullPath) {
-				// Use du for folded directories (much faster)
-				wg.Add(1)
-				go func(path string) {
-					defer wg.Done()
-					size, err := getDirectorySizeFromDu(path)
-					if err == nil && size > 0 {
-						atomic.AddInt64(&total, size)
-						atomic.AddInt64(bytesScanned, size)
-						atomic.AddInt64(dirsScanned, 1)
-					}
-				}(fullPath)
-				continue
-			}
-
-			// Recursively scan subdirectory in parallel
-			wg.Add(1)
-			go func(path string) {
-				defer wg.Done()
-				sem <- struct{}{}
-				defer func() { <-sem }()
-
-				size := calculateDirSizeConcurrent(path, largeFileChan, filesScanned, dirsScanned, bytesScanned, currentPath)
-				atomic.AddInt64(&total, size)
-				atomic.AddInt64(dirsScanned, 1)
-			}(fullPath)
-			continue
-		}
-
-		// Handle files
-		info, err := child.Info()
-		if err != nil {
-			continue
-		}
-
-		size := getActualFileSize(fullPath, info)
-		total += size
-		atomic.AddInt64(filesScanned, 1)
-		atomic.AddInt64(bytesScanned, size)
-
-		// Track large files
-		if !shouldSkipFileForLargeTracking(fullPath) && size >= minLargeFileSize {
-			largeFileChan <- fileEntry{Name: child.Name(), Path: fullPath, Size: size}
-		}
-
-		// Update current path
-		if currentPath != nil {
-			*currentPath = fullPath
-		}
-	}
-
-	wg.Wait()
-	return total
+func shouldSkipFileForLargeTracking(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return skipExtensions[ext]
 }
 
-// measureOverviewSize calculates the size of a directory using multiple strategies.
-func measureOverviewSize(path string) (int64, error) {
+// measureOverviewSize sizes a directory for the overview, reporting into
+// progress. It gives up when ctx is cancelled.
+func measureOverviewSize(ctx context.Context, path string, progress sizeProgress) (int64, error) {
 	if path == "" {
 		return 0, fmt.Errorf("empty path")
 	}
@@ -511,26 +401,33 @@ func measureOverviewSize(path string) (int64, error) {
 		return cached, nil
 	}
 
-	if duSize, err := getDirectorySizeFromDu(path); err == nil && duSize > 0 {
-		_ = storeOverviewSize(path, duSize)
-		return duSize, nil
+	state := &scanState{links: newHardlinkSet()}
+	sized, err := sizeDir(ctx, path, state, rulesForDir(path), progress)
+	if err == nil {
+		crossCheckWithDu(ctx, path, sized.Size)
+		_ = storeOverviewSize(path, sized.Size)
+		return sized.Size, nil
 	}
-
-	if logicalSize, err := getDirectoryLogicalSize(path); err == nil && logicalSize > 0 {
-		_ = storeOverviewSize(path, logicalSize)
-		return logicalSize, nil
+	if err := ctx.Err(); err != nil {
+		return 0, err
 	}
 
-	if cached, err := loadCacheFromDisk(path); err == nil {
-		_ = storeOverviewSize(path, cached.TotalSize)
-		return cached.TotalSize, nil
+	if cached, err := loadCacheFromDisk(path, scanOptions{}); err == nil {
+		_ = storeOverviewSize(path, cached.Tree.Size)
+		return cached.Tree.Size, nil
 	}
 
-	return 0, fmt.Errorf("unable to measure directory size with fast methods")
+	return 0, fmt.Errorf("unable to measure directory size: %v", err)
 }
 
-func getDirectorySizeFromDu(path string) (int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), duTimeout)
+// getDirectorySizeFromDu runs du -sk. Sizes are measured natively; du only
+// serves the -du-check cross-check. The process is killed when ctx is
+// cancelled or after duTimeout.
+func getDirectorySizeFromDu(ctx context.Context, path string) (int64, error) {
+	duRuns.Add(1)
+	defer duRuns.Done()
+
+	ctx, cancel := context.WithTimeout(ctx, duTimeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "du", "-sk", path)
@@ -542,6 +439,9 @@ func getDirectorySizeFromDu(path string) (int64, error) {
 		if ctx.Err() == context.DeadlineExceeded {
 			return 0, fmt.Errorf("du timeout after %v", duTimeout)
 		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
 		if stderr.Len() > 0 {
 			return 0, fmt.Errorf("du failed: %v (%s)", err, stderr.String())
 		}
@@ -561,31 +461,6 @@ func getDirectorySizeFromDu(path string) (int64, error) {
 	return kb * 1024, nil
 }
 
-func getDirectoryLogicalSize(path string) (int64, error) {
-	var total int64
-	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
-		if err != nil {
-			if os.IsPermission(err) {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if d.IsDir() {
-			return nil
-		}
-		info, err := d.Info()
-		if err != nil {
-			return nil
-		}
-		total += getActualFileSize(p, info)
-		return nil
-	})
-	if err != nil && err != filepath.SkipDir {
-		return 0, err
-	}
-	return total, nil
-}
-
 func getActualFileSize(_ string, info fs.FileInfo) int64 {
 	stat, ok := info.Sys().(*syscall.Stat_t)
 	if !ok {
@@ -619,3 +494,17 @@ func getLastAccessTimeFromInfo(info fs.FileInfo) time.Time {
 	}
 	return atime
 }
+
+// waitForDuExit gives killed du processes up to timeout to be reaped, so none
+// outlive the analyzer.
+func waitForDuExit(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		duRuns.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
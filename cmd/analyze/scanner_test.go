@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTree creates the files of tree below root, with their parent
+// directories. Paths ending in / are created as empty directories.
+func writeTree(t *testing.T, root string, tree map[string]string) {
+	t.Helper()
+	for name, content := range tree {
+		path := filepath.Join(root, name)
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scanFixture scans root without the user's analyze.conf.
+func scanFixture(t *testing.T, root string) scanResult {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	resetRules()
+	t.Cleanup(resetRules)
+
+	var files, dirs, bytes int64
+	var current string
+	result, err := scanPathConcurrent(context.Background(), root, scanOptions{}, &files, &dirs, &bytes, &current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestScanCounts(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"a.txt":                     "a",
+		"src/b.go":                  "b",
+		"src/lib/c.go":              "c",
+		"src/lib/d.go":              "d",
+		"node_modules/x/index.js":   "x",
+		"node_modules/x/package.js": "x",
+		"node_modules/y/index.js":   "y",
+		"node_modules/y/lib/z.js":   "z",
+		"node_modules/.bin/":        "",
+	})
+	if err := os.Symlink("a.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	result := scanFixture(t, root)
+	tests := []struct {
+		name        string
+		files, dirs int64
+	}{
+		{"a.txt", 1, 0},
+		{"link →", 1, 0},
+		{"src", 3, 1},
+		// Folded, so the counts come from sizing it
+		{"node_modules", 4, 4},
+	}
+	for _, tt := range tests {
+		var found *dirEntry
+		for i := range result.Entries {
+			if result.Entries[i].Name == tt.name {
+				found = &result.Entries[i]
+			}
+		}
+		if found == nil {
+			t.Errorf("%s not listed", tt.name)
+			continue
+		}
+		if found.FileCount != tt.files {
+			t.Errorf("%s: FileCount = %d, want %d", tt.name, found.FileCount, tt.files)
+		}
+		if node := result.Tree.find(found.Path); found.IsDir && node.DirCount != tt.dirs {
+			t.Errorf("%s: DirCount = %d, want %d", tt.name, node.DirCount, tt.dirs)
+		}
+	}
+
+	if result.Tree.FileCount != 9 {
+		t.Errorf("root FileCount = %d, want 9", result.Tree.FileCount)
+	}
+	if result.Tree.DirCount != 7 {
+		t.Errorf("root DirCount = %d, want 7", result.Tree.DirCount)
+	}
+}
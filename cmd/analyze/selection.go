@@ -0,0 +1,65 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// toggleMark marks or unmarks entry for a batch delete.
+func (m *model) toggleMark(entry dirEntry) {
+	if m.marked == nil {
+		m.marked = make(map[string]dirEntry)
+	}
+	if _, ok := m.marked[entry.Path]; ok {
+		delete(m.marked, entry.Path)
+		return
+	}
+	m.marked[entry.Path] = entry
+}
+
+func (m model) isMarked(path string) bool {
+	_, ok := m.marked[path]
+	return ok
+}
+
+// markedTargets returns the marked entries sorted by path, dropping any entry
+// that lives inside another marked directory.
+func (m model) markedTargets() []dirEntry {
+	if len(m.marked) == 0 {
+		return nil
+	}
+	targets := make([]dirEntry, 0, len(m.marked))
+	for _, entry := range m.marked {
+		targets = append(targets, entry)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Path < targets[j].Path
+	})
+
+	kept := targets[:0]
+	for _, entry := range targets {
+		covered := false
+		for _, parent := range kept {
+			if parent.IsDir && strings.HasPrefix(entry.Path, parent.Path+string(filepath.Separator)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+// markedTotal sums the size of the marked entries without double counting.
+func (m model) markedTotal() (count int, size int64) {
+	for _, entry := range m.markedTargets() {
+		count++
+		if entry.Size > 0 {
+			size += entry.Size
+		}
+	}
+	return count, size
+}
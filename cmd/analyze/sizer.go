@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sizerReadBatch is how many entries are read per getdents round trip, so huge
+// directories are never held in memory as a whole.
+const sizerReadBatch = 512
+
+// sizeProgress are the counters a sizing run reports into. Any may be nil.
+type sizeProgress struct {
+	files, dirs, bytes *int64
+	current            *string
+	visit              func(path string, size int64) // Called for every file, from several workers at once
+}
+
+// dirSize is the outcome of sizing one tree.
+type dirSize struct {
+	Size    int64 // On-disk bytes, each hardlinked inode counted once
+	Deduped int64 // Bytes left out as repeated hardlinks
+	Files   int64
+	Dirs    int64 // Directories below the root
+	Errors  int64 // Directories that could not be read
+	Types   typeTally
+	Ages    ageBuckets
+	// Newest file access and modification; directories' own times are left
+	// out, sizing bumps their atime
+	LastAccess time.Time
+	ModTime    time.Time
+}
+
+func (d *dirSize) add(o dirSize) {
+	d.Size += o.Size
+	d.Deduped += o.Deduped
+	d.Files += o.Files
+	d.Dirs += o.Dirs
+	d.Errors += o.Errors
+	if d.Types == nil {
+		d.Types = make(typeTally)
+	}
+	d.Types.merge(o.Types)
+	d.Ages.add(o.Ages, 1)
+	if o.LastAccess.After(d.LastAccess) {
+		d.LastAccess = o.LastAccess
+	}
+	if o.ModTime.After(d.ModTime) {
+		d.ModTime = o.ModTime
+	}
+}
+
+// sizerDir is one unit of work: a directory, the rules for its children and
+// the device it lives on.
+type sizerDir struct {
+	path   string
+	rules  []pathRule
+	dev    uint64
+	hasDev bool
+}
+
+// sizerQueue is the work shared by a sizing pool. Workers push the
+// subdirectories they find and any idle worker picks them up, so one deep
+// subtree doesn't leave the rest of the pool waiting.
+type sizerQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	dirs    []sizerDir
+	active  int // Workers currently reading a directory
+	stopped bool
+}
+
+func newSizerQueue() *sizerQueue {
+	q := &sizerQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *sizerQueue) push(dirs []sizerDir) {
+	if len(dirs) == 0 {
+		return
+	}
+	q.mu.Lock()
+	q.dirs = append(q.dirs, dirs...)
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// pop blocks until there is a directory to read. It returns false once the
+// queue is drained with no worker left to add more, or after stop.
+func (q *sizerQueue) pop() (sizerDir, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.dirs) == 0 && q.active > 0 && !q.stopped {
+		q.cond.Wait()
+	}
+	if len(q.dirs) == 0 || q.stopped {
+		return sizerDir{}, false
+	}
+	// Depth first keeps the queue short and the disk access local
+	dir := q.dirs[len(q.dirs)-1]
+	q.dirs = q.dirs[:len(q.dirs)-1]
+	q.active++
+	return dir, true
+}
+
+func (q *sizerQueue) done() {
+	q.mu.Lock()
+	q.active--
+	idle := q.active == 0 && len(q.dirs) == 0
+	q.mu.Unlock()
+	if idle {
+		q.cond.Broadcast()
+	}
+}
+
+func (q *sizerQueue) stop() {
+	q.mu.Lock()
+	q.stopped = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+// sizeDir measures the tree at root natively with a bounded pool of workers.
+// It honours skip rules, counts hardlinks once via state.links and treats
+// mount points like the scanner does per state.opts. Unreadable directories
+// are counted in Errors; only an unreadable root is an error. On cancellation
+// the partial result is returned together with ctx.Err().
+func sizeDir(ctx context.Context, root string, state *scanState, rules []pathRule, progress sizeProgress) (dirSize, error) {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return dirSize{}, err
+	}
+	if !info.IsDir() {
+		return dirSize{}, fmt.Errorf("%s is not a directory", root)
+	}
+	// Fail loudly when the root itself can't be listed instead of reporting 0
+	dir, err := os.Open(root)
+	if err != nil {
+		return dirSize{}, err
+	}
+	dir.Close()
+
+	rootDev, hasRootDev := deviceOf(info)
+	queue := newSizerQueue()
+	queue.push([]sizerDir{{path: root, rules: rules, dev: rootDev, hasDev: hasRootDev}})
+	stopOnCancel := context.AfterFunc(ctx, queue.stop)
+	defer stopOnCancel()
+
+	// Sizing waits on the disk far more than on the CPU
+	numWorkers := runtime.NumCPU() * cpuMultiplier
+	if numWorkers < minWorkers {
+		numWorkers = minWorkers
+	}
+	if numWorkers > maxDirWorkers {
+		numWorkers = maxDirWorkers
+	}
+
+	now := time.Now()
+	var mu sync.Mutex
+	var total dirSize
+	var wg sync.WaitGroup
+	for range numWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := sizerWorker{state: state, progress: progress, now: now, result: dirSize{Types: make(typeTally)}}
+			for {
+				dir, ok := queue.pop()
+				if !ok {
+					break
+				}
+				queue.push(w.readDir(dir))
+				queue.done()
+			}
+			w.flush()
+			mu.Lock()
+			total.add(w.result)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return total, ctx.Err()
+}
+
+// sizerWorker accumulates locally and publishes progress in batches to keep
+// atomic traffic low.
+type sizerWorker struct {
+	state    *scanState
+	progress sizeProgress
+	now      time.Time // Age buckets are as of the start
+	result   dirSize
+
+	pendingFiles, pendingDirs, pendingBytes int64
+}
+
+// readDir sizes the files of dir and returns its subdirectories.
+func (w *sizerWorker) readDir(dir sizerDir) []sizerDir {
+	f, err := os.Open(dir.path)
+	if err != nil {
+		w.result.Errors++
+		w.state.addUnreadable(dir.path)
+		return nil
+	}
+	defer f.Close()
+
+	var subdirs []sizerDir
+	// The listing is read in batches, so the ignore file may come after
+	// entries it applies to; read it before evaluating any of them
+	rules := withIgnoreFile(dir.rules, dir.path)
+	for {
+		entries, err := f.ReadDir(sizerReadBatch)
+		for _, entry := range entries {
+			path := filepath.Join(dir.path, entry.Name())
+			isDir := entry.IsDir()
+			if evalRules(rules, path, isDir) == ruleSkip {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+
+			if isDir {
+				dev, hasDev := deviceOf(info)
+				if hasDev && dir.hasDev && dev != dir.dev {
+					if w.state.opts.mountSkipReason(mountFSType(path)) != "" {
+						continue
+					}
+				}
+				subdirs = append(subdirs, sizerDir{path: path, rules: rules, dev: dev, hasDev: hasDev})
+				w.result.Dirs++
+				w.pendingDirs++
+				continue
+			}
+
+			fullSize := getActualFileSize(path, info)
+			size := w.state.links.countedSize(info, fullSize)
+			w.result.Size += size
+			w.result.Deduped += fullSize - size
+			w.result.Files++
+			w.result.Types.addFile(entry.Name(), size)
+			lastAccess, modTime := getLastAccessTimeFromInfo(info), info.ModTime()
+			w.result.Ages.addFile(size, lastAccess, modTime, w.now)
+			if lastAccess.After(w.result.LastAccess) {
+				w.result.LastAccess = lastAccess
+			}
+			if modTime.After(w.result.ModTime) {
+				w.result.ModTime = modTime
+			}
+			if w.progress.visit != nil {
+				w.progress.visit(path, size)
+			}
+			w.pendingFiles++
+			w.pendingBytes += size
+			if w.pendingFiles >= batchUpdateSize {
+				if w.progress.current != nil {
+					*w.progress.current = path
+				}
+				w.flush()
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			w.result.Errors++
+			w.state.addUnreadable(dir.path)
+			break
+		}
+	}
+	return subdirs
+}
+
+func (w *sizerWorker) flush() {
+	addProgress(w.progress.files, &w.pendingFiles)
+	addProgress(w.progress.dirs, &w.pendingDirs)
+	addProgress(w.progress.bytes, &w.pendingBytes)
+}
+
+func addProgress(counter, pending *int64) {
+	if counter != nil && *pending != 0 {
+		atomic.AddInt64(counter, *pending)
+	}
+	*pending = 0
+}
+
+// duCheck enables comparing native sizes with du -sk (the -du-check flag).
+var duCheck bool
+
+// duMismatchTolerance is the relative difference to du that gets reported.
+// Some difference is expected: du also counts directory blocks and rounds
+// every file up to whole blocks.
+const duMismatchTolerance = 0.1
+
+var (
+	duMismatchMu sync.Mutex
+	duMismatches []string
+)
+
+// crossCheckWithDu runs du on path when -du-check is set and records sizes
+// that disagree with the native result.
+func crossCheckWithDu(ctx context.Context, path string, size int64) {
+	if !duCheck {
+		return
+	}
+	duSize, err := getDirectorySizeFromDu(ctx, path)
+	var note string
+	switch {
+	case err != nil:
+		if ctx.Err() != nil {
+			return
+		}
+		note = fmt.Sprintf("%s: du failed: %v", path, err)
+	case float64(abs64(duSize-size)) > float64(max(duSize, size))*duMismatchTolerance:
+		note = fmt.Sprintf("%s: native %s, du %s", path, humanizeBytes(size), humanizeBytes(duSize))
+	default:
+		return
+	}
+	duMismatchMu.Lock()
+	duMismatches = append(duMismatches, note)
+	duMismatchMu.Unlock()
+}
+
+// reportDuMismatches prints what -du-check found once the analyzer is done.
+func reportDuMismatches(w io.Writer) {
+	if !duCheck {
+		return
+	}
+	duMismatchMu.Lock()
+	defer duMismatchMu.Unlock()
+	if len(duMismatches) == 0 {
+		fmt.Fprintln(w, "du check: all sizes agree")
+		return
+	}
+	sort.Strings(duMismatches)
+	fmt.Fprintf(w, "du check: %d sizes differ\n", len(duMismatches))
+	for _, note := range duMismatches {
+		fmt.Fprintf(w, "  %s\n", note)
+	}
+}
+
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
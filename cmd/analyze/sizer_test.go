@@ -0,0 +1,48 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A folded directory is only sized, its counts must still add up.
+func TestFoldedDirCounts(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"node_modules/a/index.js":  "a",
+		"node_modules/a/README.md": "a",
+		"node_modules/b/lib/b.js":  "b",
+		"node_modules/b/logo.png":  "b",
+		"main.go":                  "m",
+	})
+	result := scanFixture(t, root)
+
+	path := filepath.Join(root, "node_modules")
+	node := result.Tree.find(path)
+	if node == nil || !node.Collapsed {
+		t.Fatalf("node_modules was not folded: %+v", node)
+	}
+	if node.FileCount != 4 || node.DirCount != 3 {
+		t.Errorf("node_modules has %d files and %d dirs, want 4 and 3", node.FileCount, node.DirCount)
+	}
+	var typed int64
+	for _, total := range node.Types {
+		typed += total.Files
+	}
+	if typed != node.FileCount {
+		t.Errorf("file types count %d files, want %d", typed, node.FileCount)
+	}
+
+	m := model{tree: result.Tree, entries: result.Entries, path: root}
+	for i, entry := range m.entries {
+		if entry.Path == path {
+			m.selected = i
+		}
+	}
+	m.detail = readEntryDetail(path)
+	pane := strings.Join(m.detailLines(detailPaneWidth), "\n")
+	if !strings.Contains(pane, "4 files, 3 dirs") {
+		t.Errorf("details pane does not show the counts:\n%s", pane)
+	}
+}
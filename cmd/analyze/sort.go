@@ -0,0 +1,106 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+type sortOrder int
+
+const (
+	sortBySize sortOrder = iota
+	sortByName
+	sortByModTime
+	sortByAccessTime
+	sortByFileCount
+	sortOrderCount
+)
+
+func (o sortOrder) String() string {
+	switch o {
+	case sortByName:
+		return "name"
+	case sortByModTime:
+		return "modified"
+	case sortByAccessTime:
+		return "accessed"
+	case sortByFileCount:
+		return "files"
+	default:
+		return "size"
+	}
+}
+
+// arrow shows the direction of the order in the header: ↓ largest/newest
+// first, ↑ smallest/oldest/A first.
+func (o sortOrder) arrow() string {
+	switch o {
+	case sortByName, sortByModTime, sortByAccessTime:
+		return "↑"
+	default:
+		return "↓"
+	}
+}
+
+// sortEntries orders entries in place. Size and file count put the largest
+// first, name sorts A-Z, and the time orders put the oldest (stalest) first
+// with unknown times last. Ties fall back to size.
+func sortEntries(entries []dirEntry, order sortOrder) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		switch order {
+		case sortByName:
+			an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+			if an != bn {
+				return an < bn
+			}
+		case sortByModTime:
+			if less, decided := olderFirst(a.ModTime, b.ModTime); decided {
+				return less
+			}
+		case sortByAccessTime:
+			if less, decided := olderFirst(a.LastAccess, b.LastAccess); decided {
+				return less
+			}
+		case sortByFileCount:
+			if a.FileCount != b.FileCount {
+				return a.FileCount > b.FileCount
+			}
+		}
+		return a.Size > b.Size
+	})
+}
+
+func olderFirst(a, b time.Time) (less, decided bool) {
+	switch {
+	case a.Equal(b):
+		return false, false
+	case a.IsZero():
+		return false, true
+	case b.IsZero():
+		return true, true
+	default:
+		return a.Before(b), true
+	}
+}
+
+// applySort re-sorts the listing (and the unfiltered copy) with the current
+// order, keeping the cursor on the same entry.
+func (m *model) applySort() {
+	var selectedPath string
+	if m.selected >= 0 && m.selected < len(m.entries) {
+		selectedPath = m.entries[m.selected].Path
+	}
+	sortEntries(m.entries, m.sortOrder)
+	if m.filterOn {
+		sortEntries(m.filterEntries, m.sortOrder)
+	}
+	for i, entry := range m.entries {
+		if entry.Path == selectedPath {
+			m.selected = i
+			break
+		}
+	}
+	m.clampEntrySelection()
+}
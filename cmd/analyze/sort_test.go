@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSortEntriesScanned(t *testing.T) {
+	root := t.TempDir()
+	writeTree(t, root, map[string]string{
+		"big/video.mp4":           strings.Repeat("v", 256<<10),
+		"archive.zip":             strings.Repeat("z", 64<<10),
+		"src/main.go":             "package main",
+		"src/util.go":             "package main",
+		"node_modules/a/index.js": "a",
+		"node_modules/a/lib.js":   "a",
+		"node_modules/b/index.js": "b",
+		"node_modules/c/index.js": "c",
+		"node_modules/d/index.js": "d",
+	})
+	result := scanFixture(t, root)
+
+	tests := []struct {
+		order sortOrder
+		want  []string
+	}{
+		// Folded node_modules has the most files, ties fall back to size
+		{sortByFileCount, []string{"node_modules", "src", "big", "archive.zip"}},
+		{sortBySize, []string{"big", "archive.zip"}},
+		{sortByName, []string{"archive.zip", "big", "node_modules", "src"}},
+	}
+	for _, tt := range tests {
+		entries := append([]dirEntry(nil), result.Entries...)
+		sortEntries(entries, tt.order)
+		var got []string
+		for _, entry := range entries[:len(tt.want)] {
+			got = append(got, entry.Name)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("sorted by %s: %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
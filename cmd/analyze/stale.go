@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	ageBucketCount     = 4
+	defaultStaleBucket = 2  // Candidates are unused for over 90 days unless changed
+	maxStaleCandidates = 50 // Largest stale directories and files listed
+)
+
+// ageBucketLimits separate the age buckets: <30d, 30–90d, 90d–1y and >1y.
+var ageBucketLimits = [ageBucketCount - 1]time.Duration{
+	30 * 24 * time.Hour,
+	90 * 24 * time.Hour,
+	365 * 24 * time.Hour,
+}
+
+var ageBucketLabels = [ageBucketCount]string{"<30d", "30–90d", "90d–1y", ">1y"}
+
+// ageBuckets are the bytes of a subtree per age bucket, by last use (the
+// newer of access and modification) and by last modification alone.
+type ageBuckets struct {
+	Used     [ageBucketCount]int64
+	Modified [ageBucketCount]int64
+}
+
+// addFile counts a file into the buckets as of now.
+func (a *ageBuckets) addFile(size int64, lastAccess, modTime, now time.Time) {
+	a.Used[ageBucket(lastUsed(lastAccess, modTime), now)] += size
+	a.Modified[ageBucket(modTime, now)] += size
+}
+
+// add adds o, or subtracts it when sign is -1.
+func (a *ageBuckets) add(o ageBuckets, sign int64) {
+	for i := range a.Used {
+		a.Used[i] += sign * o.Used[i]
+		a.Modified[i] += sign * o.Modified[i]
+	}
+}
+
+// ageBucket returns the bucket of something last touched at t. Unknown times
+// count as recent, so they are never offered for deletion.
+func ageBucket(t, now time.Time) int {
+	if t.IsZero() {
+		return 0
+	}
+	age := now.Sub(t)
+	for i, limit := range ageBucketLimits {
+		if age < limit {
+			return i
+		}
+	}
+	return ageBucketCount - 1
+}
+
+// lastUsed is the newer of the access and modification times: writing a file
+// doesn't update its access time.
+func lastUsed(lastAccess, modTime time.Time) time.Time {
+	if lastAccess.After(modTime) {
+		return lastAccess
+	}
+	return modTime
+}
+
+// staleCandidates returns the largest entries below node that have not been
+// used (or modified) for at least the age of bucket. A directory is a
+// candidate only when nothing inside it is newer, and then replaces its
+// contents in the list.
+func staleCandidates(node *dirNode, bucket int, byModified bool, now time.Time) []dirEntry {
+	var candidates []dirEntry
+	var walk func(n *dirNode)
+	walk = func(n *dirNode) {
+		for _, child := range n.Children {
+			if child.MountSkip != "" {
+				continue
+			}
+			touched := child.ModTime
+			if !byModified {
+				touched = lastUsed(child.LastAccess, child.ModTime)
+			}
+			if ageBucket(touched, now) >= bucket {
+				candidates = append(candidates, child.entry())
+				continue
+			}
+			if child.hasListing() {
+				walk(child)
+			}
+		}
+	}
+	if node.hasListing() {
+		walk(node)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Size > candidates[j].Size
+	})
+	if len(candidates) > maxStaleCandidates {
+		candidates = candidates[:maxStaleCandidates]
+	}
+	return candidates
+}
+
+// openStale shows the age breakdown of the current directory.
+func (m *model) openStale() {
+	node := m.tree.find(m.path)
+	if node == nil {
+		return
+	}
+	m.showStale = true
+	m.staleAtime = atimeMode(m.path)
+	// Without access times, ages can only go by modification
+	m.staleByModified = m.staleAtime == "noatime"
+	if m.staleBucket == 0 {
+		m.staleBucket = defaultStaleBucket
+	}
+	m.status = ""
+	m.refreshStale()
+}
+
+func (m *model) refreshStale() {
+	m.staleRows = staleCandidates(m.tree.find(m.path), m.staleBucket, m.staleByModified, time.Now())
+	if m.staleSelected >= len(m.staleRows) {
+		m.staleSelected = max(len(m.staleRows)-1, 0)
+	}
+	m.staleOffset = clampOffset(m.staleSelected, m.staleOffset, m.staleViewport())
+}
+
+// staleViewport is the number of candidates shown below the buckets.
+func (m model) staleViewport() int {
+	return max(calculateViewport(m.height, false)-ageBucketCount-3, 1)
+}
+
+// staleBuckets returns the buckets shown, by use or by modification.
+func (m model) staleBuckets() [ageBucketCount]int64 {
+	node := m.tree.find(m.path)
+	if node == nil {
+		return [ageBucketCount]int64{}
+	}
+	if m.staleByModified {
+		return node.Ages.Modified
+	}
+	return node.Ages.Used
+}
+
+// updateStaleKey handles keys while the stale data view is shown. Marking
+// and deleting go through the same flow as the main listing.
+func (m model) updateStaleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "a", "b", "left", "h":
+		m.showStale = false
+	case "up", "k":
+		if m.staleSelected > 0 {
+			m.staleSelected--
+		}
+		m.staleOffset = clampOffset(m.staleSelected, m.staleOffset, m.staleViewport())
+	case "down", "j":
+		if m.staleSelected < len(m.staleRows)-1 {
+			m.staleSelected++
+		}
+		m.staleOffset = clampOffset(m.staleSelected, m.staleOffset, m.staleViewport())
+	case "tab":
+		// Switch between last use and last modification
+		m.staleByModified = !m.staleByModified
+		m.refreshStale()
+	case "s":
+		// Cycle the minimum age: 30 days, 90 days, 1 year
+		m.staleBucket = m.staleBucket%(ageBucketCount-1) + 1
+		m.refreshStale()
+	case " ":
+		if len(m.staleRows) > 0 {
+			m.toggleMark(m.staleRows[m.staleSelected])
+			if m.staleSelected < len(m.staleRows)-1 {
+				m.staleSelected++
+				m.staleOffset = clampOffset(m.staleSelected, m.staleOffset, m.staleViewport())
+			}
+		}
+	case "delete", "backspace", "D":
+		targets := m.markedTargets()
+		if len(targets) == 0 && len(m.staleRows) > 0 {
+			targets = []dirEntry{m.staleRows[m.staleSelected]}
+		}
+		if len(targets) == 0 {
+			return m, nil
+		}
+		// The confirmation and progress are shown by the main view
+		m.showStale = false
+		m.requestDelete(targets, msg.String() == "D")
+	}
+	return m, nil
+}
+
+// staleAtimeNote explains how far access times can be trusted here.
+func (m model) staleAtimeNote() (string, string) {
+	switch m.staleAtime {
+	case "noatime":
+		return "This filesystem is mounted noatime: access times are never updated, so ages go by modification", colorYellow
+	case "relatime":
+		return "Mounted relatime: access times are updated at most once a day", colorGray
+	default:
+		return "", ""
+	}
+}
+
+// staleTitle describes the candidates listed, e.g. "Not used for over 90 days".
+func (m model) staleTitle() string {
+	verb := "used"
+	if m.staleByModified {
+		verb = "modified"
+	}
+	limit := ageBucketLimits[m.staleBucket-1]
+	if limit >= 365*24*time.Hour {
+		return fmt.Sprintf("Not %s for over a year", verb)
+	}
+	return fmt.Sprintf("Not %s for over %d days", verb, int(limit.Hours()/24))
+}
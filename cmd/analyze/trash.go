@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// trashInfoTimeFormat is the DeletionDate layout required by the
+// freedesktop.org Trash specification (local time, no zone).
+const trashInfoTimeFormat = "2006-01-02T15:04:05"
+
+// trashLocation is a trash directory together with the prefix that .trashinfo
+// Path entries are written relative to ("" means absolute paths).
+type trashLocation struct {
+	TopDir  string
+	FilesIn string
+	InfoIn  string
+}
+
+// moveToTrash moves path into the trash that belongs to its filesystem and
+// returns the new location of the item.
+func moveToTrash(path string) (string, error) {
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("path must be absolute: %s", path)
+	}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "darwin" {
+		return moveToDarwinTrash(path)
+	}
+
+	loc, err := trashLocationFor(path, info)
+	if err != nil {
+		return "", err
+	}
+	return moveToTrashLocation(path, loc, time.Now())
+}
+
+// trashLocationFor picks the home trash when path lives on the same filesystem
+// as $XDG_DATA_HOME, otherwise the per-mount trash of path's filesystem.
+func trashLocationFor(path string, info os.FileInfo) (trashLocation, error) {
+	dev, ok := deviceOf(info)
+	if !ok {
+		return trashLocation{}, fmt.Errorf("cannot determine filesystem of %s", path)
+	}
+
+	dataHome, err := xdgDataHome()
+	if err != nil {
+		return trashLocation{}, err
+	}
+	if err := os.MkdirAll(dataHome, 0700); err != nil {
+		return trashLocation{}, err
+	}
+	if homeInfo, err := os.Stat(dataHome); err == nil {
+		if homeDev, ok := deviceOf(homeInfo); ok && homeDev == dev {
+			return newTrashLocation(filepath.Join(dataHome, "Trash"), "")
+		}
+	}
+
+	topDir, err := mountTopDir(path, dev)
+	if err != nil {
+		return trashLocation{}, err
+	}
+	uid := strconv.Itoa(os.Getuid())
+
+	// Method 1: an administrator-created $topdir/.Trash with the sticky bit set
+	shared := filepath.Join(topDir, ".Trash")
+	if sharedInfo, err := os.Lstat(shared); err == nil &&
+		sharedInfo.IsDir() &&
+		sharedInfo.Mode()&os.ModeSymlink == 0 &&
+		sharedInfo.Mode()&os.ModeSticky != 0 {
+		if loc, err := newTrashLocation(filepath.Join(shared, uid), topDir); err == nil {
+			return loc, nil
+		}
+	}
+
+	// Method 2: a private $topdir/.Trash-$uid
+	return newTrashLocation(filepath.Join(topDir, ".Trash-"+uid), topDir)
+}
+
+func newTrashLocation(dir, topDir string) (trashLocation, error) {
+	loc := trashLocation{
+		TopDir:  topDir,
+		FilesIn: filepath.Join(dir, "files"),
+		InfoIn:  filepath.Join(dir, "info"),
+	}
+	if err := os.MkdirAll(loc.FilesIn, 0700); err != nil {
+		return trashLocation{}, err
+	}
+	if err := os.MkdirAll(loc.InfoIn, 0700); err != nil {
+		return trashLocation{}, err
+	}
+	return loc, nil
+}
+
+// moveToTrashLocation reserves a unique name by creating the .trashinfo file
+// exclusively, then renames the item into files/.
+func moveToTrashLocation(path string, loc trashLocation, deletedAt time.Time) (string, error) {
+	infoPath := path
+	if loc.TopDir != "" {
+		rel, err := filepath.Rel(loc.TopDir, path)
+		if err == nil && !strings.HasPrefix(rel, "..") {
+			infoPath = rel
+		}
+	}
+	contents := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		escapeTrashPath(infoPath), deletedAt.Format(trashInfoTimeFormat))
+
+	base := filepath.Base(path)
+	for attempt := 1; attempt < 10000; attempt++ {
+		name := trashCandidateName(base, attempt)
+		target := filepath.Join(loc.FilesIn, name)
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+
+		infoFile := filepath.Join(loc.InfoIn, name+".trashinfo")
+		file, err := os.OpenFile(infoFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			if os.IsExist(err) {
+				continue
+			}
+			return "", err
+		}
+		_, writeErr := file.WriteString(contents)
+		closeErr := file.Close()
+		if writeErr == nil {
+			writeErr = closeErr
+		}
+		if writeErr != nil {
+			_ = os.Remove(infoFile)
+			return "", writeErr
+		}
+
+		if err := os.Rename(path, target); err != nil {
+			_ = os.Remove(infoFile)
+			if errors.Is(err, syscall.EXDEV) {
+				return "", fmt.Errorf("trash for %s is on another filesystem", path)
+			}
+			return "", err
+		}
+		return target, nil
+	}
+	return "", fmt.Errorf("no free name in trash for %s", base)
+}
+
+// trashCandidateName returns base for the first attempt and "stem.N.ext" after that.
+func trashCandidateName(base string, attempt int) string {
+	if attempt == 1 {
+		return base
+	}
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	if stem == "" {
+		stem, ext = base, ""
+	}
+	return fmt.Sprintf("%s.%d%s", stem, attempt, ext)
+}
+
+// escapeTrashPath URL-escapes each path segment as the spec requires.
+func escapeTrashPath(path string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// mountTopDir walks up from path until the parent is on another device.
+func mountTopDir(path string, dev uint64) (string, error) {
+	current := filepath.Dir(path)
+	for {
+		parent := filepath.Dir(current)
+		if parent == current {
+			return current, nil
+		}
+		info, err := os.Stat(parent)
+		if err != nil {
+			return "", err
+		}
+		parentDev, ok := deviceOf(info)
+		if !ok || parentDev != dev {
+			return current, nil
+		}
+		current = parent
+	}
+}
+
+func moveToDarwinTrash(path string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	trashDir := filepath.Join(home, ".Trash")
+	base := filepath.Base(path)
+	for attempt := 1; attempt < 10000; attempt++ {
+		target := filepath.Join(trashDir, trashCandidateName(base, attempt))
+		if _, err := os.Lstat(target); err == nil {
+			continue
+		}
+		if err := os.Rename(path, target); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+	return "", fmt.Errorf("no free name in trash for %s", base)
+}
+
+func xdgDataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+func deviceOf(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// dirNode is one file or directory in a scanned tree. Directory nodes carry
+// aggregates for their whole subtree, so drilling down reuses them instead of
+// rescanning.
+type dirNode struct {
+	Name       string
+	Path       string
+	Size       int64
+	IsDir      bool
+	IsSymlink  bool           // Listed, never followed; Name ends in " →"
+	LastAccess time.Time      // Newest file access in the subtree
+	ModTime    time.Time      // Newest modification in the subtree
+	FileCount  int64          // Files in the subtree (1 for a file node)
+	DirCount   int64          // Directories in the subtree, excluding the node itself
+	Deduped    int64          // Bytes of repeated hardlinks left out of Size
+	Children   []*dirNode     // Sorted by size, largest first
+	LargeFiles []fileEntry    // Largest files anywhere in the subtree
+	Collapsed  bool           // Children were dropped; entering the node needs a rescan
+	Truncated  bool           // Only the largest maxTreeChildren children were kept
+	IsMount    bool           // The directory is the root of another filesystem
+	MountFS    string         // Filesystem type of a mount point, when known
+	MountSkip  string         // Why a mount point was not scanned, "" when it was
+	LargeIndex largeIndexInfo // Index that added to LargeFiles, set on the scan root
+	ScannedAt  time.Time      // When the scan started, set on the scan root
+	Types      []typeTotal    // Bytes per file extension in the subtree, largest first
+	Ages       ageBuckets     // Bytes per age bucket in the subtree, as of the scan
+}
+
+// hasListing reports whether the node can be shown without rescanning.
+func (n *dirNode) hasListing() bool {
+	return n != nil && n.IsDir && !n.Collapsed
+}
+
+// find returns the node for path inside the tree rooted at n, or nil.
+func (n *dirNode) find(path string) *dirNode {
+	if n == nil {
+		return nil
+	}
+	path = filepath.Clean(path)
+	if path == n.Path {
+		return n
+	}
+	rel, err := filepath.Rel(n.Path, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil
+	}
+
+	current := n
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		var next *dirNode
+		for _, child := range current.Children {
+			if child.IsDir && filepath.Base(child.Path) == part {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		current = next
+	}
+	return current
+}
+
+// pathTo returns the nodes from n down to the node for path, or nil when
+// path is not in the tree.
+func (n *dirNode) pathTo(path string) []*dirNode {
+	target := n.find(path)
+	if target == nil {
+		return nil
+	}
+	var chain []*dirNode
+	for current := n; ; {
+		chain = append(chain, current)
+		if current == target {
+			return chain
+		}
+		for _, child := range current.Children {
+			if child.IsDir && isSameOrBelow(target.Path, child.Path) {
+				current = child
+				break
+			}
+		}
+	}
+}
+
+// applyChildDelta updates the aggregates of n after a descendant changed from
+// before to after, without needing n's full listing.
+func (n *dirNode) applyChildDelta(before dirNode, after *dirNode) {
+	n.Size += after.Size - before.Size
+	n.Deduped += after.Deduped - before.Deduped
+	n.FileCount += after.FileCount - before.FileCount
+	n.DirCount += after.DirCount - before.DirCount
+	if after.ModTime.After(n.ModTime) {
+		n.ModTime = after.ModTime
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		return n.Children[i].Size > n.Children[j].Size
+	})
+
+	large := make([]fileEntry, 0, len(n.LargeFiles)+len(after.LargeFiles))
+	for _, file := range n.LargeFiles {
+		if !isSameOrBelow(file.Path, after.Path) {
+			large = append(large, file)
+		}
+	}
+	n.LargeFiles = topLargeFiles(append(large, after.LargeFiles...), largeFileLimit)
+
+	types := make(typeTally, len(n.Types))
+	types.addTotals(n.Types, 1)
+	types.addTotals(before.Types, -1)
+	types.addTotals(after.Types, 1)
+	n.Types = types.totals()
+	n.Ages.add(before.Ages, -1)
+	n.Ages.add(after.Ages, 1)
+}
+
+// addLargeFiles merges large files found outside the scan, such as those of
+// folded directories from an index, into every node on their path, so they
+// are still listed after drilling down.
+func (n *dirNode) addLargeFiles(files []fileEntry) {
+	found := make(map[*dirNode][]fileEntry)
+	for _, file := range files {
+		for current := n; current != nil; {
+			found[current] = append(found[current], file)
+			var next *dirNode
+			for _, child := range current.Children {
+				if child.IsDir && isSameOrBelow(file.Path, child.Path) {
+					next = child
+					break
+				}
+			}
+			current = next
+		}
+	}
+	for node, files := range found {
+		node.LargeFiles = mergeLargeFiles(node.LargeFiles, files)
+	}
+}
+
+// setChildren stores the non-nil nodes from slots as children and recomputes
+// the node's aggregates from them.
+func (n *dirNode) setChildren(slots []*dirNode) {
+	children := make([]*dirNode, 0, len(slots))
+	var large []fileEntry
+	types := make(typeTally)
+
+	n.Size = 0
+	n.FileCount = 0
+	n.DirCount = 0
+	n.Deduped = 0
+	n.Ages = ageBuckets{}
+	now := time.Now()
+	for _, child := range slots {
+		if child == nil {
+			continue
+		}
+		children = append(children, child)
+		n.Size += child.Size
+		n.Deduped += child.Deduped
+		if child.LastAccess.After(n.LastAccess) {
+			n.LastAccess = child.LastAccess
+		}
+		if child.ModTime.After(n.ModTime) {
+			n.ModTime = child.ModTime
+		}
+		if child.IsDir {
+			n.DirCount += 1 + child.DirCount
+			n.FileCount += child.FileCount
+			large = append(large, child.LargeFiles...)
+			types.addTotals(child.Types, 1)
+			n.Ages.add(child.Ages, 1)
+			continue
+		}
+		n.FileCount++
+		types.addFile(child.Name, child.Size)
+		n.Ages.addFile(child.Size, child.LastAccess, child.ModTime, now)
+		if child.Size >= minLargeFileSize && !shouldSkipFileForLargeTracking(child.Path) {
+			large = append(large, fileEntry{Name: child.Name, Path: child.Path, Size: child.Size})
+		}
+	}
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Size > children[j].Size
+	})
+	n.Children = children
+	n.LargeFiles = topLargeFiles(large, largeFileLimit)
+	n.Types = types.totals()
+}
+
+// compact bounds the memory held by a finished subtree: tiny subtrees drop
+// their children entirely and large fan-outs keep only their biggest members.
+// Aggregates are left untouched.
+func (n *dirNode) compact() {
+	if n.Size < treeCollapseSize {
+		n.Children = nil
+		n.Collapsed = true
+		return
+	}
+	if len(n.Children) > maxTreeChildren {
+		n.Children = n.Children[:maxTreeChildren]
+		n.Truncated = true
+	}
+}
+
+// entry converts the node to the flat row shown in the listing.
+func (n *dirNode) entry() dirEntry {
+	return dirEntry{
+		Name:       n.Name,
+		Path:       n.Path,
+		Size:       n.Size,
+		IsDir:      n.IsDir,
+		IsSymlink:  n.IsSymlink,
+		LastAccess: n.LastAccess,
+		ModTime:    n.ModTime,
+		FileCount:  n.FileCount,
+		IsMount:    n.IsMount,
+		MountFS:    n.MountFS,
+		MountSkip:  n.MountSkip,
+	}
+}
+
+// result builds the listing for the node's immediate children.
+func (n *dirNode) result() scanResult {
+	entries := make([]dirEntry, 0, len(n.Children))
+	for _, child := range n.Children {
+		entries = append(entries, child.entry())
+	}
+	return scanResult{
+		Entries:    entries,
+		LargeFiles: cloneFileEntries(n.LargeFiles),
+		TotalSize:  n.Size,
+		Tree:       n,
+	}
+}
+
+func topLargeFiles(files []fileEntry, limit int) []fileEntry {
+	if len(files) == 0 {
+		return nil
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+	return files
+}
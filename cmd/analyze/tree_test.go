@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+// Large files from an index belong to every directory above them, so drilling
+// down from memory keeps them.
+func TestAddLargeFiles(t *testing.T) {
+	deps := &dirNode{Name: "node_modules", Path: "/p/app/node_modules", IsDir: true, Collapsed: true}
+	app := &dirNode{Name: "app", Path: "/p/app", IsDir: true, Children: []*dirNode{
+		deps,
+		{Name: "main.go", Path: "/p/app/main.go"},
+	}}
+	docs := &dirNode{Name: "docs", Path: "/p/docs", IsDir: true}
+	root := &dirNode{Name: "p", Path: "/p", IsDir: true, Children: []*dirNode{app, docs}}
+	scanned := fileEntry{Name: "video.mp4", Path: "/p/docs/video.mp4", Size: 300 << 20}
+	root.LargeFiles = []fileEntry{scanned}
+	docs.LargeFiles = []fileEntry{scanned}
+
+	root.addLargeFiles([]fileEntry{
+		{Name: "core.bin", Path: "/p/app/node_modules/x/core.bin", Size: 200 << 20},
+		{Name: "video.mp4", Path: "/p/docs/video.mp4", Size: 300 << 20},
+	})
+
+	tests := []struct {
+		node  *dirNode
+		paths []string
+	}{
+		{root, []string{"/p/docs/video.mp4", "/p/app/node_modules/x/core.bin"}},
+		{app, []string{"/p/app/node_modules/x/core.bin"}},
+		{deps, []string{"/p/app/node_modules/x/core.bin"}},
+		{docs, []string{"/p/docs/video.mp4"}},
+	}
+	for _, tt := range tests {
+		large := tt.node.result().LargeFiles
+		if len(large) != len(tt.paths) {
+			t.Errorf("%s lists %d large files, want %d: %+v", tt.node.Path, len(large), len(tt.paths), large)
+			continue
+		}
+		for i, path := range tt.paths {
+			if large[i].Path != path {
+				t.Errorf("%s large file %d is %s, want %s", tt.node.Path, i, large[i].Path, path)
+			}
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	maxTypeExtensions = 32 // Extensions kept per tree node, the rest is summed per category
+	maxTypeFiles      = 50 // Largest files listed for a category
+	maxTypeExtLabels  = 4  // Extensions named next to a category
+
+	noExtension     = "(none)"
+	otherExtensions = "(other)"
+	otherCategory   = "Other"
+)
+
+// fileCategories lists the categories of the type breakdown and the
+// extensions that belong to each. Anything else is otherCategory. An
+// extension belongs to one category only (.ts is TypeScript, not a video
+// transport stream).
+var fileCategories = []struct {
+	name string
+	exts []string
+}{
+	{"Video", []string{".mp4", ".mkv", ".mov", ".avi", ".wmv", ".flv", ".webm", ".m4v", ".mpg", ".mpeg", ".m2ts", ".3gp"}},
+	{"Audio", []string{".mp3", ".flac", ".wav", ".aac", ".m4a", ".ogg", ".opus", ".wma", ".aiff", ".alac"}},
+	{"Images", []string{".jpg", ".jpeg", ".png", ".gif", ".heic", ".heif", ".webp", ".tif", ".tiff", ".bmp", ".raw", ".cr2", ".cr3", ".nef", ".arw", ".dng", ".psd", ".svg", ".ico"}},
+	{"Archives", []string{".zip", ".tar", ".gz", ".tgz", ".bz2", ".xz", ".zst", ".7z", ".rar", ".lz4", ".lzma", ".cpio"}},
+	{"Disk images", []string{".iso", ".img", ".dmg", ".sparseimage", ".sparsebundle", ".toast", ".bin", ".cue"}},
+	{"VM images", []string{".vmdk", ".vdi", ".qcow2", ".qcow", ".vhd", ".vhdx", ".ova", ".ovf", ".hdd", ".vmem", ".vmsn"}},
+	{"Packages", []string{".deb", ".rpm", ".pkg", ".apk", ".appimage", ".snap", ".flatpak", ".msi", ".exe", ".whl", ".jar", ".war", ".gem", ".nupkg", ".xpi", ".crx"}},
+	{"Databases", []string{".db", ".sqlite", ".sqlite3", ".db3", ".mdb", ".accdb", ".ldb", ".realm", ".frm", ".ibd", ".mdf", ".ndf", ".wal"}},
+	{"Build artifacts", []string{".o", ".a", ".so", ".dylib", ".dll", ".lib", ".obj", ".class", ".pyc", ".pyo", ".rlib", ".rmeta", ".wasm", ".pdb", ".dsym", ".pch", ".gch"}},
+	{"Documents", []string{".pdf", ".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx", ".odt", ".ods", ".odp", ".pages", ".numbers", ".key", ".epub", ".rtf"}},
+	{"Code", []string{".go", ".js", ".ts", ".tsx", ".jsx", ".py", ".rb", ".java", ".kt", ".rs", ".swift", ".m", ".mm", ".c", ".cpp", ".h", ".hpp", ".cs", ".php", ".sh", ".css", ".scss", ".html", ".vue", ".json", ".yml", ".yaml", ".xml", ".sql", ".md", ".txt", ".toml", ".lock"}},
+}
+
+var extensionCategory = func() map[string]string {
+	byExt := make(map[string]string)
+	for _, category := range fileCategories {
+		for _, ext := range category.exts {
+			byExt[ext] = category.name
+		}
+	}
+	return byExt
+}()
+
+// fileExtension returns the lower-cased extension of name as used by the
+// breakdown, noExtension when it has none.
+func fileExtension(name string) string {
+	base := filepath.Base(strings.TrimSuffix(name, " →"))
+	ext := strings.ToLower(filepath.Ext(base))
+	if len(ext) <= 1 || len(ext) == len(base) || len(ext) > 12 {
+		// Dotfiles and long "extensions" of dotted names aren't types
+		return noExtension
+	}
+	return ext
+}
+
+func categoryOf(ext string) string {
+	if category, ok := extensionCategory[ext]; ok {
+		return category
+	}
+	return otherCategory
+}
+
+// typeTotal is the bytes and files of one extension in a subtree. Ext is
+// otherExtensions for the extensions of Category that did not fit.
+type typeTotal struct {
+	Category string
+	Ext      string
+	Size     int64
+	Files    int64
+}
+
+// typeTally sums typeTotals while a subtree is being measured.
+type typeTally map[string]*typeTotal
+
+func (t typeTally) addFile(name string, size int64) {
+	ext := fileExtension(name)
+	t.add(typeTotal{Category: categoryOf(ext), Ext: ext, Size: size, Files: 1}, 1)
+}
+
+// add adds total, or subtracts it when sign is -1.
+func (t typeTally) add(total typeTotal, sign int64) {
+	key := total.Ext
+	if key == otherExtensions {
+		key += total.Category
+	}
+	current, ok := t[key]
+	if !ok {
+		current = &typeTotal{Category: total.Category, Ext: total.Ext}
+		t[key] = current
+	}
+	current.Size += sign * total.Size
+	current.Files += sign * total.Files
+}
+
+func (t typeTally) addTotals(totals []typeTotal, sign int64) {
+	for _, total := range totals {
+		t.add(total, sign)
+	}
+}
+
+func (t typeTally) merge(o typeTally) {
+	for _, total := range o {
+		t.add(*total, 1)
+	}
+}
+
+// totals returns the tally largest first. Beyond maxTypeExtensions the
+// smallest extensions are folded into their category's otherExtensions entry,
+// so category totals stay exact.
+func (t typeTally) totals() []typeTotal {
+	if len(t) == 0 {
+		return nil
+	}
+	totals := make([]typeTotal, 0, len(t))
+	for _, total := range t {
+		if total.Files > 0 {
+			totals = append(totals, *total)
+		}
+	}
+	sortTypeTotals(totals)
+	if len(totals) <= maxTypeExtensions {
+		return totals
+	}
+
+	kept := make(typeTally, maxTypeExtensions)
+	for i, total := range totals {
+		if i >= maxTypeExtensions-len(fileCategories)-1 {
+			total.Ext = otherExtensions
+		}
+		kept.add(total, 1)
+	}
+	totals = totals[:0]
+	for _, total := range kept {
+		totals = append(totals, *total)
+	}
+	sortTypeTotals(totals)
+	return totals
+}
+
+func sortTypeTotals(totals []typeTotal) {
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Size != totals[j].Size {
+			return totals[i].Size > totals[j].Size
+		}
+		return totals[i].Ext < totals[j].Ext
+	})
+}
+
+// typeCategory is one row of the breakdown.
+type typeCategory struct {
+	Name  string
+	Size  int64
+	Files int64
+	Exts  []typeTotal // Largest first
+}
+
+// groupTypes sums the extensions of a node per category, largest first.
+func groupTypes(totals []typeTotal) []typeCategory {
+	byName := make(map[string]*typeCategory)
+	var categories []*typeCategory
+	for _, total := range totals {
+		category, ok := byName[total.Category]
+		if !ok {
+			category = &typeCategory{Name: total.Category}
+			byName[total.Category] = category
+			categories = append(categories, category)
+		}
+		category.Size += total.Size
+		category.Files += total.Files
+		category.Exts = append(category.Exts, total)
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Size > categories[j].Size
+	})
+
+	rows := make([]typeCategory, len(categories))
+	for i, category := range categories {
+		rows[i] = *category
+	}
+	return rows
+}
+
+// extLabel names the largest extensions of a category, e.g. ".mp4 12 GB, .mkv 3 GB".
+func (c typeCategory) extLabel() string {
+	parts := make([]string, 0, maxTypeExtLabels)
+	for i, total := range c.Exts {
+		if i == maxTypeExtLabels {
+			parts = append(parts, "…")
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s %s", total.Ext, humanizeBytes(total.Size)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type typeFilesMsg struct {
+	path     string
+	category string
+	files    []fileEntry
+	err      error
+}
+
+// findTypeFilesCmd walks path again for the largest files of category. The
+// tree only keeps totals per extension, which is all the breakdown needs.
+func findTypeFilesCmd(ctx context.Context, path, category string, opts scanOptions) tea.Cmd {
+	return func() tea.Msg {
+		var mu sync.Mutex
+		var files []fileEntry
+		progress := sizeProgress{visit: func(file string, size int64) {
+			if categoryOf(fileExtension(file)) != category {
+				return
+			}
+			mu.Lock()
+			files = append(files, fileEntry{Name: filepath.Base(file), Path: file, Size: size})
+			if len(files) > maxTypeFiles*8 {
+				files = topLargeFiles(files, maxTypeFiles)
+			}
+			mu.Unlock()
+		}}
+		state := &scanState{opts: opts, links: newHardlinkSet()}
+		_, err := sizeDir(ctx, path, state, rulesForDir(path), progress)
+		return typeFilesMsg{path: path, category: category, files: topLargeFiles(files, maxTypeFiles), err: err}
+	}
+}
+
+// openTypes shows the breakdown of the current directory.
+func (m *model) openTypes() {
+	node := m.tree.find(m.path)
+	if node == nil {
+		return
+	}
+	m.showTypes = true
+	m.typeRows = groupTypes(node.Types)
+	m.typeSelected = 0
+	m.typeOffset = 0
+	m.typeCategory = ""
+	m.status = ""
+	if len(m.typeRows) == 0 && node.Size > 0 {
+		// Cached by a version that didn't record types
+		m.status = "This scan has no file type details, press r to rescan"
+	}
+}
+
+// closeTypeFiles leaves the largest files of a category, stopping the search.
+func (m *model) closeTypeFiles() {
+	if m.typeFilesCancel != nil {
+		m.typeFilesCancel()
+		m.typeFilesCancel = nil
+	}
+	m.typeCategory = ""
+	m.typeFiles = nil
+	m.typeFilesLoading = false
+	m.status = ""
+}
+
+// updateTypesKey handles keys while the type breakdown is shown.
+func (m model) updateTypesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	inFiles := m.typeCategory != ""
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "e":
+		m.closeTypeFiles()
+		m.showTypes = false
+	case "up", "k":
+		if inFiles {
+			if m.typeFileSelected > 0 {
+				m.typeFileSelected--
+			}
+			m.typeFileOffset = clampListOffset(m.typeFileSelected, m.typeFileOffset, m.height)
+		} else if m.typeSelected > 0 {
+			m.typeSelected--
+			m.typeOffset = clampListOffset(m.typeSelected, m.typeOffset, m.height)
+		}
+	case "down", "j":
+		if inFiles {
+			if m.typeFileSelected < len(m.typeFiles)-1 {
+				m.typeFileSelected++
+			}
+			m.typeFileOffset = clampListOffset(m.typeFileSelected, m.typeFileOffset, m.height)
+		} else if m.typeSelected < len(m.typeRows)-1 {
+			m.typeSelected++
+			m.typeOffset = clampListOffset(m.typeSelected, m.typeOffset, m.height)
+		}
+	case "enter", "right", "l":
+		if inFiles || len(m.typeRows) == 0 {
+			return m, nil
+		}
+		category := m.typeRows[m.typeSelected].Name
+		ctx, cancel := context.WithCancel(m.ctx)
+		m.typeCategory = category
+		m.typeFiles = nil
+		m.typeFileSelected = 0
+		m.typeFileOffset = 0
+		m.typeFilesLoading = true
+		m.typeFilesCancel = cancel
+		m.status = fmt.Sprintf("Looking for the largest %s files...", strings.ToLower(category))
+		return m, findTypeFilesCmd(ctx, m.path, category, m.scanOpts)
+	case "b", "left", "h":
+		if inFiles {
+			m.closeTypeFiles()
+			return m, nil
+		}
+		m.showTypes = false
+	}
+	return m, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Every extension belongs to one category; a second listing would be dead.
+func TestFileCategoriesUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for _, category := range fileCategories {
+		for _, ext := range category.exts {
+			if ext != strings.ToLower(ext) || !strings.HasPrefix(ext, ".") {
+				t.Errorf("%s: %q is not a lower-case extension", category.name, ext)
+			}
+			if other, ok := seen[ext]; ok {
+				t.Errorf("%s is listed in both %s and %s", ext, other, category.name)
+			}
+			seen[ext] = category.name
+		}
+	}
+}
+
+func TestCategoryOf(t *testing.T) {
+	tests := map[string]string{
+		"movie.MKV":   "Video",
+		"app.ts":      "Code",
+		"disk.qcow2":  "VM images",
+		"Makefile":    otherCategory,
+		".bashrc":     otherCategory,
+		"backup.tar":  "Archives",
+		"photo.heic":  "Images",
+		"notes.weird": otherCategory,
+	}
+	for name, want := range tests {
+		if got := categoryOf(fileExtension(name)); got != want {
+			t.Errorf("categoryOf(%s) = %s, want %s", name, got, want)
+		}
+	}
+}
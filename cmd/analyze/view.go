@@ -8,34 +8,63 @@ import (
 	"sync/atomic"
 )
 
-// View renders the TUI display.
+// View renders the TUI display, with the details pane when it is open.
 func (m model) View() string {
+	screen := m.renderScreen()
+	overlay := m.showHelp || m.showJournal || m.showSnapshots || m.showDiff || m.showTypes || m.showStale
+	if m.showDetail && !overlay {
+		return m.withDetailPane(screen)
+	}
+	return screen
+}
+
+// renderScreen renders the view on screen.
+func (m model) renderScreen() string {
 	var b strings.Builder
 	fmt.Fprintln(&b)
 
+	if m.showHelp {
+		m.renderHelp(&b)
+		return b.String()
+	}
+	if m.showJournal {
+		m.renderJournal(&b)
+		return b.String()
+	}
+	if m.showSnapshots {
+		m.renderSnapshots(&b)
+		return b.String()
+	}
+	if m.showDiff {
+		m.renderDiff(&b)
+		return b.String()
+	}
+	if m.showTypes {
+		m.renderTypes(&b)
+		return b.String()
+	}
+	if m.showStale {
+		m.renderStale(&b)
+		return b.String()
+	}
+
 	if m.inOverviewMode() {
 		fmt.Fprintf(&b, "%sAnalyze Disk%s\n", colorPurpleBold, colorReset)
-		if m.overviewScanning {
+		if m.gotoTyping {
+			fmt.Fprintf(&b, "%s\n\n", m.gotoLine())
+		} else if m.overviewScanning {
 			// Check if we're in initial scan (all entries are pending)
-			allPending := true
-			for _, entry := range m.entries {
-				if entry.Size >= 0 {
-					allPending = false
-					break
-				}
-			}
-
-			if allPending {
+			if !hasMeasuredOverviewEntries(m.entries) {
 				// Show prominent loading screen for initial scan
-				fmt.Fprintf(&b, "%s%s%s%s Analyzing disk usage, please wait...%s\n",
+				fmt.Fprintf(&b, "%s%s%s%s Analyzing disk usage, please wait...%s%s\n",
 					colorCyan, colorBold,
 					spinnerFrames[m.spinner],
-					colorReset, colorReset)
+					colorReset, colorReset, m.overviewProgressText())
 				return b.String()
 			} else {
 				// Progressive scanning - show subtle indicator
 				fmt.Fprintf(&b, "%sSelect a location to explore:%s  ", colorGray, colorReset)
-				fmt.Fprintf(&b, "%s%s%s%s Scanning...\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset)
+				fmt.Fprintf(&b, "%s%s%s%s Scanning...%s\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, m.overviewProgressText())
 			}
 		} else {
 			// Check if there are still pending items
@@ -48,17 +77,61 @@ func (m model) View() string {
 			}
 			if hasPending {
 				fmt.Fprintf(&b, "%sSelect a location to explore:%s  ", colorGray, colorReset)
-				fmt.Fprintf(&b, "%s%s%s%s Scanning...\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset)
+				fmt.Fprintf(&b, "%s%s%s%s Scanning...%s\n\n", colorCyan, colorBold, spinnerFrames[m.spinner], colorReset, m.overviewProgressText())
 			} else {
 				fmt.Fprintf(&b, "%sSelect a location to explore:%s\n\n", colorGray, colorReset)
 			}
 		}
 	} else {
-		fmt.Fprintf(&b, "%sAnalyze Disk%s  %s%s%s", colorPurpleBold, colorReset, colorGray, displayPath(m.path), colorReset)
+		fmt.Fprintf(&b, "%sAnalyze Disk%s  %s", colorPurpleBold, colorReset, m.renderBreadcrumbs())
 		if !m.scanning {
 			fmt.Fprintf(&b, "  |  Total: %s", humanizeBytes(m.totalSize))
+			if node := m.tree.find(m.path); node != nil && node.Deduped > 0 {
+				// Matches du: each hardlinked inode is counted once
+				fmt.Fprintf(&b, "  %s(hardlinks: -%s)%s", colorGray, humanizeBytes(node.Deduped), colorReset)
+			}
+			if m.scanOpts.OneFileSystem {
+				fmt.Fprintf(&b, "  |  %sone filesystem%s", colorGray, colorReset)
+			} else if m.scanOpts.IncludeRemote {
+				fmt.Fprintf(&b, "  |  %sincl. network/FUSE%s", colorGray, colorReset)
+			}
+			if !m.showLargeFiles {
+				fmt.Fprintf(&b, "  |  %sSort: %s %s%s", colorGray, m.sortOrder, m.sortOrder.arrow(), colorReset)
+				if position := listPosition(m.offset, m.listRows(), calculateViewport(m.height, false)); position != "" {
+					fmt.Fprintf(&b, "  |  %s%s%s", colorGray, position, colorReset)
+				}
+			} else {
+				var index largeIndexInfo
+				if m.tree.find(m.path) != nil {
+					// The index is asked once per scan, for the whole tree
+					index = m.tree.LargeIndex
+				}
+				indexColor := colorGray
+				if index.stale() {
+					// Files added since the last updatedb are only known to the scan
+					indexColor = colorYellow
+				}
+				fmt.Fprintf(&b, "  |  %sLarge files: %s%s", indexColor, index.label(), colorReset)
+			}
+			if m.watch != nil {
+				limit := ""
+				if m.watchLimited {
+					limit = " (limit reached)"
+				}
+				fmt.Fprintf(&b, "  |  %sWatching %s dirs%s%s", colorGreen, formatNumber(int64(m.watchCount)), limit, colorReset)
+			}
+		}
+		switch {
+		case m.gotoTyping:
+			fmt.Fprintf(&b, "\n%s\n", m.gotoLine())
+		case m.crumbFocus:
+			fmt.Fprintf(&b, "\n%s←→  |  Enter Go up  |  ESC cancel%s\n", colorGray, colorReset)
+		case m.filterOn:
+			// The filter prompt takes the blank separator line so the viewport is unchanged
+			fmt.Fprintf(&b, "\n%s\n", m.filterLine())
+		default:
+			fmt.Fprintf(&b, "\n\n")
 		}
-		fmt.Fprintf(&b, "\n\n")
 	}
 
 	if m.deleting {
@@ -68,11 +141,18 @@ func (m model) View() string {
 			count = atomic.LoadInt64(m.deleteCount)
 		}
 
-		fmt.Fprintf(&b, "%s%s%s%s Deleting: %s%s items%s removed, please wait...\n",
-			colorCyan, colorBold,
-			spinnerFrames[m.spinner],
-			colorReset,
-			colorYellow, formatNumber(count), colorReset)
+		if m.deletePermanent {
+			fmt.Fprintf(&b, "%s%s%s%s Deleting: %s%s items%s removed, please wait...\n",
+				colorCyan, colorBold,
+				spinnerFrames[m.spinner],
+				colorReset,
+				colorYellow, formatNumber(count), colorReset)
+		} else {
+			fmt.Fprintf(&b, "%s%s%s%s Moving to Trash, please wait...\n",
+				colorCyan, colorBold,
+				spinnerFrames[m.spinner],
+				colorReset)
+		}
 
 		return b.String()
 	}
@@ -101,7 +181,9 @@ func (m model) View() string {
 	}
 
 	if m.showLargeFiles {
-		if len(m.largeFiles) == 0 {
+		if len(m.largeFiles) == 0 && m.filterOn {
+			fmt.Fprintln(&b, "  No matching large files")
+		} else if len(m.largeFiles) == 0 {
 			fmt.Fprintln(&b, "  No large files found (>=100MB)")
 		} else {
 			viewport := calculateViewport(m.height, true)
@@ -124,12 +206,12 @@ func (m model) View() string {
 				shortPath := displayPath(file.Path)
 				shortPath = truncateMiddle(shortPath, 35)
 				paddedPath := padName(shortPath, 35)
-				entryPrefix := "   "
+				entryPrefix := markPrefix(m.isMarked(file.Path), false)
 				nameColor := ""
 				sizeColor := colorGray
 				numColor := ""
 				if idx == m.largeSelected {
-					entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+					entryPrefix = markPrefix(m.isMarked(file.Path), true)
 					nameColor = colorCyan
 					sizeColor = colorCyan
 					numColor = colorCyan
@@ -141,7 +223,9 @@ func (m model) View() string {
 			}
 		}
 	} else {
-		if len(m.entries) == 0 {
+		if len(m.entries) == 0 && m.filterOn {
+			fmt.Fprintln(&b, "  No matching entries")
+		} else if len(m.entries) == 0 {
 			fmt.Fprintln(&b, "  Empty directory")
 		} else {
 			if m.inOverviewMode() {
@@ -209,6 +293,9 @@ func (m model) View() string {
 					} else {
 						// For overview mode, get access time on-demand if not set
 						lastAccess := entry.LastAccess
+						if m.sortOrder == sortByModTime {
+							lastAccess = entry.ModTime
+						}
 						if lastAccess.IsZero() && entry.Path != "" {
 							lastAccess = getLastAccessTime(entry.Path)
 						}
@@ -242,8 +329,8 @@ func (m model) View() string {
 					start = 0
 				}
 				end := start + viewport
-				if end > len(m.entries) {
-					end = len(m.entries)
+				if end > m.shownEntries() {
+					end = m.shownEntries()
 				}
 
 				for idx := start; idx < end; idx++ {
@@ -276,12 +363,12 @@ func (m model) View() string {
 					}
 
 					// Keep chart columns aligned even when arrow is shown
-					entryPrefix := "   "
+					entryPrefix := markPrefix(m.isMarked(entry.Path), false)
 					nameSegment := fmt.Sprintf("%s %s", icon, paddedName)
 					numColor := ""
 					percentColor := ""
 					if idx == m.selected {
-						entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+						entryPrefix = markPrefix(m.isMarked(entry.Path), true)
 						nameSegment = fmt.Sprintf("%s%s %s%s", colorCyan, icon, paddedName, colorReset)
 						numColor = colorCyan
 						percentColor = colorCyan
@@ -290,13 +377,21 @@ func (m model) View() string {
 
 					displayIndex := idx + 1
 
-					// Priority: cleanable > unused time
+					// Priority: watched change > mount point > cleanable > unused time
 					var hintLabel string
-					if entry.IsDir && isCleanableDir(entry.Path) {
+					if orig, changed := m.changedSince(entry); changed {
+						label, _ := diffRow{Old: orig, New: entry.Size, State: compareSizes(orig, entry.Size)}.diffLabel()
+						hintLabel = fmt.Sprintf("%schanged %s%s", colorYellow, label, colorReset)
+					} else if label := mountLabel(entry); label != "" {
+						hintLabel = fmt.Sprintf("%s%s%s", colorBlue, label, colorReset)
+					} else if entry.IsDir && isCleanableDir(entry.Path) {
 						hintLabel = fmt.Sprintf("%s🧹%s", colorYellow, colorReset)
 					} else {
 						// Get access time on-demand if not set
 						lastAccess := entry.LastAccess
+						if m.sortOrder == sortByModTime {
+							lastAccess = entry.ModTime
+						}
 						if lastAccess.IsZero() && entry.Path != "" {
 							lastAccess = getLastAccessTime(entry.Path)
 						}
@@ -315,6 +410,16 @@ func (m model) View() string {
 							nameSegment, sizeColor, size, colorReset, hintLabel)
 					}
 				}
+
+				if count, size := m.otherEntries(); count > 0 && end-start < viewport {
+					var percent float64
+					if m.totalSize > 0 {
+						percent = float64(size) / float64(m.totalSize) * 100
+					}
+					label := padName(fmt.Sprintf("%s other items", formatNumber(int64(count))), 28)
+					fmt.Fprintf(&b, "       %s %5.1f%%  |  %s   %s %10s  z Show all%s\n",
+						coloredProgressBar(size, maxSize, percent), percent, colorGray, label, humanizeBytes(size), colorReset)
+				}
 			}
 		}
 	}
@@ -323,27 +428,491 @@ func (m model) View() string {
 	if m.inOverviewMode() {
 		// Show ← Back if there's history (entered from a parent directory)
 		if len(m.history) > 0 {
-			fmt.Fprintf(&b, "%s↑↓←→  |  Enter  |  R Refresh  |  O Open  |  F Show  |  ← Back  |  Q Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s↑↓←→  |  Enter  |  o Open  |  g Go to  |  ← Back  |  ? Help  |  q Quit%s\n", colorGray, colorReset)
 		} else {
-			fmt.Fprintf(&b, "%s↑↓→  |  Enter  |  R Refresh  |  O Open  |  F Show  |  Q Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s↑↓→  |  Enter  |  o Open  |  g Go to  |  ? Help  |  q Quit%s\n", colorGray, colorReset)
 		}
 	} else if m.showLargeFiles {
-		fmt.Fprintf(&b, "%s↑↓←  |  R Refresh  |  O Open  |  F Show  |  ⌫ Delete  |  ← Back  |  Q Quit%s\n", colorGray, colorReset)
+		fmt.Fprintf(&b, "%s↑↓←  |  o Open  |  / Filter  |  ⌫ Trash  |  ← Back  |  ? Help  |  q Quit%s\n", colorGray, colorReset)
 	} else {
+		// The footer fits one line of 80 columns, the other keys are under ?
 		largeFileCount := len(m.largeFiles)
 		if largeFileCount > 0 {
-			fmt.Fprintf(&b, "%s↑↓←→  |  Enter  |  R Refresh  |  O Open  |  F Show  |  ⌫ Delete  |  T Top(%d)  |  Q Quit%s\n", colorGray, largeFileCount, colorReset)
+			fmt.Fprintf(&b, "%s↑↓←→  |  o Open  |  / Filter  |  ⌫ Trash  |  t Top(%d)  |  ? Help  |  q Quit%s\n", colorGray, largeFileCount, colorReset)
 		} else {
-			fmt.Fprintf(&b, "%s↑↓←→  |  Enter  |  R Refresh  |  O Open  |  F Show  |  ⌫ Delete  |  Q Quit%s\n", colorGray, colorReset)
+			fmt.Fprintf(&b, "%s↑↓←→  |  o Open  |  / Filter  |  ⌫ Trash  |  D Delete  |  ? Help  |  q Quit%s\n", colorGray, colorReset)
 		}
 	}
-	if m.deleteConfirm && m.deleteTarget != nil {
+	if count, size := m.markedTotal(); count > 0 && !m.deleteConfirm {
+		fmt.Fprintf(&b, "%sSelected:%s %d items, %s  %sSpace toggle  |  ⌫ Trash all  |  D Delete all  |  ESC clear%s\n",
+			colorYellow, colorReset, count, humanizeBytes(size), colorGray, colorReset)
+	}
+	if len(m.deleteFailures) > 0 && !m.deleteConfirm {
+		fmt.Fprintf(&b, "%s%s%s\n", colorRed, m.status, colorReset)
+		for i, failure := range m.deleteFailures {
+			if i == maxFailureLines {
+				fmt.Fprintf(&b, "%s  ... and %d more%s\n", colorGray, len(m.deleteFailures)-i, colorReset)
+				break
+			}
+			fmt.Fprintf(&b, "%s  %s: %v%s\n", colorGray, truncateMiddle(displayPath(failure.path), 40), failure.err, colorReset)
+		}
+	} else if m.status != "" && !m.deleteConfirm {
+		fmt.Fprintf(&b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+	if m.deleteConfirm && len(m.deleteTargets) > 0 {
 		fmt.Fprintln(&b)
-		fmt.Fprintf(&b, "%sDelete:%s %s (%s)  %sPress ⌫ again  |  ESC cancel%s\n",
-			colorRed, colorReset,
-			m.deleteTarget.Name, humanizeBytes(m.deleteTarget.Size),
-			colorGray, colorReset)
+		label := m.deleteTargets[0].Name
+		if len(m.deleteTargets) > 1 {
+			label = fmt.Sprintf("%d items", len(m.deleteTargets))
+		}
+		size := sumKnownEntrySizes(m.deleteTargets)
+		if len(m.deleteFailures) > 0 {
+			fmt.Fprintf(&b, "%sProtected, left out: %s — %v%s\n",
+				colorGray, truncateMiddle(displayPath(m.deleteFailures[0].path), 40), m.deleteFailures[0].err, colorReset)
+			if len(m.deleteFailures) > 1 {
+				fmt.Fprintf(&b, "%s  ... and %d more%s\n", colorGray, len(m.deleteFailures)-1, colorReset)
+			}
+		}
+		switch {
+		case m.deletePreview != nil:
+			for _, line := range m.deletePreview.previewLines() {
+				fmt.Fprintln(&b, line)
+			}
+		case m.previewLoading:
+			fmt.Fprintf(&b, "%sPreview: walking %s...%s\n", colorGray, label, colorReset)
+		}
+		if m.deletePermanent {
+			fmt.Fprintf(&b, "%s%sDelete permanently:%s %s (%s)  %sCannot be undone  |  Press y to confirm  |  p Preview  |  ESC cancel%s\n",
+				colorRed, colorBold, colorReset,
+				label, humanizeBytes(size),
+				colorGray, colorReset)
+		} else {
+			fmt.Fprintf(&b, "%sMove to Trash:%s %s (%s)  %sPress ⌫ again  |  P Preview  |  ESC cancel%s\n",
+				colorYellow, colorReset,
+				label, humanizeBytes(size),
+				colorGray, colorReset)
+		}
+	}
+	return b.String()
+}
+
+// overviewProgressText summarizes what overview sizing has read so far.
+func (m model) overviewProgressText() string {
+	if m.overviewFilesScanned == nil || m.overviewBytesScanned == nil {
+		return ""
+	}
+	files := atomic.LoadInt64(m.overviewFilesScanned)
+	if files == 0 {
+		return ""
+	}
+	return fmt.Sprintf("  %s%s files, %s%s", colorGray, formatNumber(files), humanizeBytes(atomic.LoadInt64(m.overviewBytesScanned)), colorReset)
+}
+
+// markPrefix renders the 3-column gutter in front of a row: the selection
+// arrow and/or the batch-delete mark.
+func markPrefix(marked, selected bool) string {
+	switch {
+	case marked && selected:
+		return fmt.Sprintf("%s%s▶%s%s●%s ", colorCyan, colorBold, colorReset, colorYellow, colorReset)
+	case marked:
+		return fmt.Sprintf(" %s●%s ", colorYellow, colorReset)
+	case selected:
+		return fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+	default:
+		return "   "
+	}
+}
+
+// renderJournal draws the deletion history with restore actions.
+func (m model) renderJournal(b *strings.Builder) {
+	fmt.Fprintf(b, "%sDeletion History%s  %s%d records%s\n\n", colorPurpleBold, colorReset, colorGray, len(m.journal), colorReset)
+
+	if len(m.journal) == 0 {
+		fmt.Fprintln(b, "  Nothing deleted from the analyzer yet")
+	} else {
+		viewport := calculateViewport(m.height, true)
+		start := m.journalOffset
+		if start < 0 {
+			start = 0
+		}
+		end := start + viewport
+		if end > len(m.journal) {
+			end = len(m.journal)
+		}
+		for idx := start; idx < end; idx++ {
+			record := m.journal[idx]
+			shortPath := truncateMiddle(displayPath(record.Path), 40)
+			paddedPath := padName(shortPath, 40)
+			entryPrefix := "   "
+			nameColor := ""
+			numColor := ""
+			stateColor := colorGray
+			if record.restorable() {
+				stateColor = colorGreen
+			}
+			if idx == m.journalSelected {
+				entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+				nameColor = colorCyan
+				numColor = colorCyan
+			}
+			fmt.Fprintf(b, "%s%s%2d.%s %s%s%s  %10s  %8s items  %-9s  %s%s%s\n",
+				entryPrefix, numColor, idx+1, colorReset,
+				nameColor, paddedPath, colorReset,
+				humanizeBytes(record.Size), formatNumber(record.Items),
+				formatAge(record.DeletedAt),
+				stateColor, journalStateLabel(record), colorReset)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  Enter Restore  |  U Restore newest..selected  |  ← Back  |  q Quit%s\n", colorGray, colorReset)
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// renderSnapshots draws the earlier scans that can be compared with.
+func (m model) renderSnapshots(b *strings.Builder) {
+	fmt.Fprintf(b, "%sScan History%s  %s%s  %d scans%s\n\n", colorPurpleBold, colorReset, colorGray, displayPath(m.path), len(m.snapshots), colorReset)
+
+	if len(m.snapshots) == 0 {
+		fmt.Fprintln(b, "  No earlier scans of this directory yet")
+	} else {
+		viewport := calculateViewport(m.height, true)
+		end := m.snapshotOffset + viewport
+		if end > len(m.snapshots) {
+			end = len(m.snapshots)
+		}
+		for idx := m.snapshotOffset; idx < end; idx++ {
+			snapshot := m.snapshots[idx]
+			entryPrefix := "   "
+			numColor := ""
+			if idx == m.snapshotSelected {
+				entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+				numColor = colorCyan
+			}
+			var change string
+			if scope := snapshotScope(snapshot, m.path); scope != "" {
+				change = fmt.Sprintf("%s%s%s", colorGray, scope, colorReset)
+			} else {
+				row := diffRow{Old: snapshot.TotalSize, New: m.totalSize, State: compareSizes(snapshot.TotalSize, m.totalSize)}
+				label, labelColor := row.diffLabel()
+				change = fmt.Sprintf("%s%s%s since", labelColor, label, colorReset)
+			}
+			fmt.Fprintf(b, "%s%s%2d.%s %s%-12s%s  %-9s  %10s  %s\n",
+				entryPrefix, numColor, idx+1, colorReset,
+				numColor, snapshot.ScanTime.Format("Jan 2 15:04"), colorReset,
+				formatAge(snapshot.ScanTime), humanizeBytes(snapshot.TotalSize), change)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  Enter Compare  |  ← Back  |  q Quit%s\n", colorGray, colorReset)
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// renderDiff draws what changed below diffPath since the chosen scan.
+func (m model) renderDiff(b *strings.Builder) {
+	fmt.Fprintf(b, "%sWhat Changed%s  %s%s  since %s (%s)%s\n",
+		colorPurpleBold, colorReset, colorGray, displayPath(m.diffPath),
+		m.diffSnapshot.ScanTime.Format("Jan 2 15:04"), formatAge(m.diffSnapshot.ScanTime), colorReset)
+	total := diffRow{Old: m.diffOld, New: m.diffNew, State: compareSizes(m.diffOld, m.diffNew)}
+	totalLabel, totalColor := total.diffLabel()
+	fmt.Fprintf(b, "Total: %s → %s  %s%s%s\n\n", humanizeBytes(m.diffOld), humanizeBytes(m.diffNew), totalColor, totalLabel, colorReset)
+
+	if len(m.diffRows) == 0 {
+		fmt.Fprintln(b, "  Empty directory")
+	} else {
+		viewport := calculateViewport(m.height, false)
+		end := m.diffOffset + viewport
+		if end > len(m.diffRows) {
+			end = len(m.diffRows)
+		}
+		for idx := m.diffOffset; idx < end; idx++ {
+			row := m.diffRows[idx]
+			icon := "📄"
+			if row.IsDir {
+				icon = "📁"
+			}
+			entryPrefix := "   "
+			nameColor := ""
+			numColor := ""
+			if idx == m.diffSelected {
+				entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+				nameColor = colorCyan
+				numColor = colorCyan
+			}
+			if row.State == diffGone {
+				nameColor = colorGray
+			}
+			oldSize, newSize := humanizeBytes(row.Old), humanizeBytes(row.New)
+			switch row.State {
+			case diffNew:
+				oldSize = "-"
+			case diffUnknown:
+				oldSize = "?"
+			case diffGone:
+				newSize = "-"
+			}
+			label, labelColor := row.diffLabel()
+			fmt.Fprintf(b, "%s%s%2d.%s %s %s%s%s  %10s → %10s  %s%s%s\n",
+				entryPrefix, numColor, idx+1, colorReset,
+				icon, nameColor, padName(trimName(row.Name), 28), colorReset,
+				oldSize, newSize, labelColor, label, colorReset)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  Enter Open  |  ← Back  |  c Close  |  q Quit%s\n", colorGray, colorReset)
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// renderTypes draws the file type breakdown, or the largest files of one
+// category once it is opened.
+func (m model) renderTypes(b *strings.Builder) {
+	if m.typeCategory != "" {
+		m.renderTypeFiles(b)
+		return
+	}
+	fmt.Fprintf(b, "%sFile Types%s  %s%s%s  |  Total: %s\n\n", colorPurpleBold, colorReset, colorGray, displayPath(m.path), colorReset, humanizeBytes(m.totalSize))
+
+	if len(m.typeRows) == 0 {
+		fmt.Fprintln(b, "  No files")
+	} else {
+		var total int64
+		for _, row := range m.typeRows {
+			total += row.Size
+		}
+		viewport := calculateViewport(m.height, false)
+		end := m.typeOffset + viewport
+		if end > len(m.typeRows) {
+			end = len(m.typeRows)
+		}
+		for idx := m.typeOffset; idx < end; idx++ {
+			row := m.typeRows[idx]
+			var percent float64
+			if total > 0 {
+				percent = float64(row.Size) / float64(total) * 100
+			}
+			bar := coloredProgressBar(row.Size, m.typeRows[0].Size, percent)
+			entryPrefix := "   "
+			nameColor := ""
+			numColor := ""
+			if idx == m.typeSelected {
+				entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+				nameColor = colorCyan
+				numColor = colorCyan
+			}
+			fmt.Fprintf(b, "%s%s%2d.%s %s %5.1f%%  |  %s%s%s %10s  %9s files  %s%s%s\n",
+				entryPrefix, numColor, idx+1, colorReset, bar, percent,
+				nameColor, padName(row.Name, 16), colorReset,
+				humanizeBytes(row.Size), formatNumber(row.Files),
+				colorGray, row.extLabel(), colorReset)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  Enter Largest files  |  ← Back  |  q Quit%s\n", colorGray, colorReset)
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// renderTypeFiles draws the largest files of the opened category.
+func (m model) renderTypeFiles(b *strings.Builder) {
+	var category typeCategory
+	for _, row := range m.typeRows {
+		if row.Name == m.typeCategory {
+			category = row
+			break
+		}
+	}
+	fmt.Fprintf(b, "%s%s%s  %s%s%s  |  %s in %s files\n",
+		colorPurpleBold, category.Name, colorReset, colorGray, displayPath(m.path), colorReset,
+		humanizeBytes(category.Size), formatNumber(category.Files))
+	fmt.Fprintf(b, "%s%s%s\n\n", colorGray, category.extLabel(), colorReset)
+
+	switch {
+	case m.typeFilesLoading:
+		fmt.Fprintf(b, "  %sSearching...%s\n", colorGray, colorReset)
+	case len(m.typeFiles) == 0:
+		fmt.Fprintln(b, "  No files found")
+	default:
+		viewport := calculateViewport(m.height, false)
+		end := m.typeFileOffset + viewport
+		if end > len(m.typeFiles) {
+			end = len(m.typeFiles)
+		}
+		for idx := m.typeFileOffset; idx < end; idx++ {
+			file := m.typeFiles[idx]
+			entryPrefix := "   "
+			nameColor := ""
+			numColor := ""
+			if idx == m.typeFileSelected {
+				entryPrefix = fmt.Sprintf(" %s%s▶%s ", colorCyan, colorBold, colorReset)
+				nameColor = colorCyan
+				numColor = colorCyan
+			}
+			fmt.Fprintf(b, "%s%s%2d.%s %10s  %s%s%s\n",
+				entryPrefix, numColor, idx+1, colorReset, humanizeBytes(file.Size),
+				nameColor, truncateMiddle(displayPath(file.Path), 70), colorReset)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  ← Back  |  e Close  |  q Quit%s\n", colorGray, colorReset)
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// renderStale draws the age buckets of m.path and the largest entries that
+// have gone unused.
+func (m model) renderStale(b *strings.Builder) {
+	by := "last use"
+	if m.staleByModified {
+		by = "last modification"
+	}
+	fmt.Fprintf(b, "%sStale Data%s  %s%s  by %s%s\n\n", colorPurpleBold, colorReset, colorGray, displayPath(m.path), by, colorReset)
+
+	buckets := m.staleBuckets()
+	var total, largest int64
+	for _, size := range buckets {
+		total += size
+		largest = max(largest, size)
+	}
+	for i, size := range buckets {
+		var percent float64
+		if total > 0 {
+			percent = float64(size) / float64(total) * 100
+		}
+		fmt.Fprintf(b, "  %-8s %s %5.1f%%  %10s\n", ageBucketLabels[i], coloredProgressBar(size, largest, percent), percent, humanizeBytes(size))
+	}
+	if note, noteColor := m.staleAtimeNote(); note != "" {
+		fmt.Fprintf(b, "%s%s%s\n", noteColor, note, colorReset)
+	}
+
+	fmt.Fprintf(b, "\n%s:\n", m.staleTitle())
+	if len(m.staleRows) == 0 {
+		fmt.Fprintln(b, "  Nothing found")
+	} else {
+		end := min(m.staleOffset+m.staleViewport(), len(m.staleRows))
+		for idx := m.staleOffset; idx < end; idx++ {
+			entry := m.staleRows[idx]
+			icon := "📄"
+			if entry.IsDir {
+				icon = "📁"
+			}
+			nameColor := ""
+			numColor := ""
+			selected := idx == m.staleSelected
+			if selected {
+				nameColor = colorCyan
+				numColor = colorCyan
+			}
+			touched := entry.ModTime
+			if !m.staleByModified {
+				touched = lastUsed(entry.LastAccess, entry.ModTime)
+			}
+			fmt.Fprintf(b, "%s%s%2d.%s %s %s%s%s %10s  %s%-7s%s\n",
+				markPrefix(m.isMarked(entry.Path), selected), numColor, idx+1, colorReset,
+				icon, nameColor, padName(truncateMiddle(displayPath(entry.Path), 50), 50), colorReset,
+				humanizeBytes(entry.Size), colorGray, formatUnusedTime(touched), colorReset)
+		}
+	}
+
+	fmt.Fprintln(b)
+	fmt.Fprintf(b, "%s↑↓  |  Tab Use/Modified  |  s Age  |  Space Select  |  ⌫ Trash  |  D Delete  |  ← Back  |  q Quit%s\n", colorGray, colorReset)
+	if count, size := m.markedTotal(); count > 0 {
+		fmt.Fprintf(b, "%sSelected:%s %d items, %s\n", colorYellow, colorReset, count, humanizeBytes(size))
+	}
+	if m.status != "" {
+		fmt.Fprintf(b, "%s%s%s\n", colorGray, m.status, colorReset)
+	}
+}
+
+// Screen lines above the first row of the main listing: a blank line, the
+// header with the path and the blank (or filter) line below it. The overview
+// has its prompt and another blank line as well.
+const (
+	headerLines         = 3
+	overviewHeaderLines = 4
+	breadcrumbColumn    = len("Analyze Disk  ") // The path starts here on the header line
+	breadcrumbLine      = 1
+)
+
+// listGeometry says where the rows of the main listing are on screen, for
+// hit-testing mouse clicks.
+type listGeometry struct {
+	top    int // Screen line of the first row shown
+	offset int // Index of the first row shown
+	rows   int // Rows shown
+}
+
+// row returns the index of the row on screen line y.
+func (g listGeometry) row(y int) (int, bool) {
+	if y < g.top || y >= g.top+g.rows {
+		return 0, false
+	}
+	return g.offset + y - g.top, true
+}
+
+// listGeometry matches the layout View draws for the listing on screen.
+func (m model) listGeometry() listGeometry {
+	switch {
+	case m.scanning || m.deleting:
+		return listGeometry{}
+	case m.showLargeFiles:
+		rows := min(calculateViewport(m.height, true), len(m.largeFiles)-m.largeOffset)
+		return listGeometry{top: headerLines, offset: m.largeOffset, rows: max(rows, 0)}
+	case m.inOverviewMode():
+		if m.overviewScanning && !hasMeasuredOverviewEntries(m.entries) {
+			return listGeometry{} // Still the loading screen
+		}
+		// The overview lists every location without scrolling
+		return listGeometry{top: overviewHeaderLines, rows: len(m.entries)}
+	default:
+		rows := min(calculateViewport(m.height, false), m.listRows()-m.offset)
+		return listGeometry{top: headerLines, offset: m.offset, rows: max(rows, 0)}
+	}
+}
+
+// breadcrumbTarget returns the directory named at column x of the path in
+// the header, e.g. ~/src for a click on "src" in ~/src/marmot. A separator
+// belongs to the name after it.
+func (m model) breadcrumbTarget(x int) (string, bool) {
+	if m.inOverviewMode() || x < breadcrumbColumn {
+		return "", false
+	}
+	crumbs := m.breadcrumbs()
+	column := breadcrumbColumn
+	for i, crumb := range crumbs {
+		column += displayWidth(breadcrumbSeparator(crumbs, i) + crumb.Label)
+		if x < column {
+			return crumb.Path, true
+		}
+	}
+	return "", false
+}
+
+// renderBreadcrumbs draws the path in the header, highlighting the directory
+// chosen while it has focus.
+func (m model) renderBreadcrumbs() string {
+	crumbs := m.breadcrumbs()
+	var b strings.Builder
+	for i, crumb := range crumbs {
+		b.WriteString(colorGray + breadcrumbSeparator(crumbs, i))
+		if m.crumbFocus && i == m.crumbSelected {
+			b.WriteString(colorReset + colorCyan + colorBold + crumb.Label + colorReset)
+		} else {
+			b.WriteString(crumb.Label)
+		}
 	}
+	b.WriteString(colorReset)
 	return b.String()
 }
 
@@ -355,7 +924,7 @@ func calculateViewport(termHeight int, isLargeFiles bool) int {
 	}
 
 	// Calculate reserved space for UI elements
-	reserved := 6 // header (3-4 lines) + footer (2 lines)
+	reserved := 6 // header (3 lines) + footer (2 lines) + status line
 	if isLargeFiles {
 		reserved = 5 // Large files view has less overhead
 	}
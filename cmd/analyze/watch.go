@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeSaveMu keeps watch mode from changing a tree while it is being written
+// to the disk cache or scan history.
+var treeSaveMu sync.RWMutex
+
+type watchEventMsg struct {
+	watcher  *dirWatcher
+	dirs     []string // Directories whose contents changed
+	overflow bool     // The kernel dropped events, some changes are missing
+	closed   bool
+}
+
+// dirRefresh is a directory measured again after a change.
+type dirRefresh struct {
+	dir     string
+	nodes   []*dirNode // Files and new directories
+	keep    []string   // Directories already in the tree that still exist
+	resized *dirSize   // New totals of a directory shown without a listing
+}
+
+type watchRefreshMsg struct {
+	refreshes []dirRefresh
+}
+
+// refreshRequest says what is known about a changed directory, so the tree
+// itself is never read off the UI goroutine.
+type refreshRequest struct {
+	dir       string
+	collapsed bool
+	known     map[string]bool // Subdirectories present in the tree
+}
+
+func waitForWatchCmd(w *dirWatcher) tea.Cmd {
+	return func() tea.Msg {
+		return w.next()
+	}
+}
+
+func refreshDirsCmd(ctx context.Context, requests []refreshRequest, opts scanOptions) tea.Cmd {
+	return func() tea.Msg {
+		state := &scanState{opts: opts, links: newHardlinkSet()}
+		var msg watchRefreshMsg
+		for _, req := range requests {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if refresh, ok := refreshDir(ctx, req, state); ok {
+				msg.refreshes = append(msg.refreshes, refresh)
+			}
+		}
+		return msg
+	}
+}
+
+// refreshDir lists req.dir again. Files are measured, directories the tree
+// already has are kept as they are (their own watches cover them) and new
+// directories are sized without a listing.
+func refreshDir(ctx context.Context, req refreshRequest, state *scanState) (dirRefresh, bool) {
+	refresh := dirRefresh{dir: req.dir}
+	rules := rulesForDir(req.dir)
+	if req.collapsed {
+		sized, err := sizeDir(ctx, req.dir, state, rules, sizeProgress{})
+		if err != nil {
+			return refresh, false
+		}
+		refresh.resized = &sized
+		return refresh, true
+	}
+
+	children, err := os.ReadDir(req.dir)
+	if err != nil {
+		return refresh, false
+	}
+	rules = childRules(rules, req.dir, children)
+	for _, child := range children {
+		path := filepath.Join(req.dir, child.Name())
+		if evalRules(rules, path, child.IsDir()) == ruleSkip {
+			continue
+		}
+		if req.known[path] {
+			refresh.keep = append(refresh.keep, path)
+			continue
+		}
+		info, err := child.Info()
+		if err != nil {
+			continue
+		}
+		if child.IsDir() {
+			sized, err := sizeDir(ctx, path, state, rules, sizeProgress{})
+			if err != nil {
+				continue
+			}
+			modTime := info.ModTime()
+			if sized.ModTime.After(modTime) {
+				modTime = sized.ModTime
+			}
+			refresh.nodes = append(refresh.nodes, &dirNode{
+				Name:       child.Name(),
+				Path:       path,
+				Size:       sized.Size,
+				IsDir:      true,
+				LastAccess: sized.LastAccess,
+				ModTime:    modTime,
+				FileCount:  sized.Files,
+				DirCount:   sized.Dirs,
+				Deduped:    sized.Deduped,
+				Collapsed:  true,
+				Types:      sized.Types.totals(),
+				Ages:       sized.Ages,
+			})
+			continue
+		}
+		name := child.Name()
+		isSymlink := info.Mode()&os.ModeSymlink != 0
+		if isSymlink {
+			name += " →"
+		}
+		fullSize := getActualFileSize(path, info)
+		size := state.links.countedSize(info, fullSize)
+		refresh.nodes = append(refresh.nodes, &dirNode{
+			Name:       name,
+			Path:       path,
+			Size:       size,
+			IsSymlink:  isSymlink,
+			LastAccess: getLastAccessTimeFromInfo(info),
+			ModTime:    info.ModTime(),
+			FileCount:  1,
+			Deduped:    fullSize - size,
+		})
+	}
+	return refresh, true
+}
+
+// watchTargets lists the directories to watch below node, breadth first so
+// the budget goes to what is on screen and one level below it first.
+func watchTargets(node *dirNode, budget int) (dirs []string, limited bool) {
+	if node == nil || !node.IsDir || budget <= 0 {
+		return nil, false
+	}
+	queue := []*dirNode{node}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if len(dirs) == budget {
+			return dirs, true
+		}
+		dirs = append(dirs, current.Path)
+		for _, child := range current.Children {
+			if child.IsDir && child.MountSkip == "" {
+				queue = append(queue, child)
+			}
+		}
+	}
+	return dirs, false
+}
+
+// toggleWatch turns watch mode on or off.
+func (m *model) toggleWatch() tea.Cmd {
+	if m.watch != nil {
+		m.watch.close()
+		m.watch = nil
+		m.watchCount = 0
+		m.watchOrig = nil
+		m.status = "Watch mode off"
+		return nil
+	}
+	watcher, err := newDirWatcher()
+	if err != nil {
+		m.status = "Cannot watch: " + err.Error()
+		return nil
+	}
+	m.watch = watcher
+	m.watchPath = ""
+	m.watchTree = nil
+	m.syncWatch()
+	m.status = "Watching for changes"
+	return waitForWatchCmd(watcher)
+}
+
+// syncWatch moves the watches to the current directory after navigating or
+// scanning.
+func (m *model) syncWatch() {
+	if m.watch == nil || (m.watchPath == m.path && m.watchTree == m.tree) {
+		return
+	}
+	if m.watchTree != m.tree {
+		// Badges are relative to the scan on screen
+		m.watchOrig = make(map[string]int64)
+	}
+	m.watchPath = m.path
+	m.watchTree = m.tree
+	var dirs []string
+	if !m.inOverviewMode() {
+		dirs, m.watchLimited = watchTargets(m.tree.find(m.path), watchLimit())
+	}
+	m.watchCount = m.watch.setDirs(dirs)
+}
+
+// handleWatchEvent asks for the changed directories to be measured again.
+func (m *model) handleWatchEvent(msg watchEventMsg) tea.Cmd {
+	if msg.closed || msg.watcher != m.watch {
+		return nil
+	}
+	if msg.overflow {
+		m.status = "Too many changes to follow, press r to rescan"
+	}
+	var requests []refreshRequest
+	for _, dir := range msg.dirs {
+		node := m.tree.find(dir)
+		if node == nil || !node.IsDir {
+			continue
+		}
+		req := refreshRequest{dir: dir, collapsed: !node.hasListing(), known: make(map[string]bool)}
+		for _, child := range node.Children {
+			if child.IsDir {
+				req.known[child.Path] = true
+			}
+		}
+		requests = append(requests, req)
+	}
+	cmds := []tea.Cmd{waitForWatchCmd(m.watch)}
+	if len(requests) > 0 {
+		cmds = append(cmds, refreshDirsCmd(m.ctx, requests, m.scanOpts))
+	}
+	return tea.Batch(cmds...)
+}
+
+// applyRefreshes updates the tree in place, then the listing on screen.
+func (m *model) applyRefreshes(refreshes []dirRefresh) {
+	if m.watch == nil || m.tree == nil || len(refreshes) == 0 {
+		return
+	}
+	treeSaveMu.Lock()
+	for _, refresh := range refreshes {
+		m.applyRefresh(refresh)
+	}
+	treeSaveMu.Unlock()
+
+	// The disk cache only notices changes to the top directory's mtime
+	invalidateCache(m.tree.Path)
+
+	if node := m.tree.find(m.path); node.hasListing() {
+		result := node.result()
+		if m.filterOn {
+			m.setFilteredListing(result.Entries, result.LargeFiles)
+		} else {
+			m.entries = result.Entries
+			m.largeFiles = result.LargeFiles
+		}
+		m.totalSize = result.TotalSize
+		m.applySort()
+		m.clampEntrySelection()
+		m.clampLargeSelection()
+	}
+}
+
+func (m *model) applyRefresh(refresh dirRefresh) {
+	chain := m.tree.pathTo(refresh.dir)
+	if len(chain) == 0 {
+		return
+	}
+	node := chain[len(chain)-1]
+	before := *node
+	for _, n := range chain {
+		m.rememberSize(n)
+	}
+	// Cached listings of these directories are out of date now
+	for _, n := range chain {
+		delete(m.cache, n.Path)
+	}
+
+	if refresh.resized != nil {
+		if node.hasListing() {
+			return // Expanded by a rescan meanwhile; its own watches cover it
+		}
+		node.Size = refresh.resized.Size
+		node.Deduped = refresh.resized.Deduped
+		node.FileCount = refresh.resized.Files
+		node.DirCount = refresh.resized.Dirs
+		node.Types = refresh.resized.Types.totals()
+		node.Ages = refresh.resized.Ages
+	} else {
+		if !node.hasListing() {
+			return
+		}
+		existing := make(map[string]*dirNode, len(node.Children))
+		for _, child := range node.Children {
+			existing[child.Path] = child
+			m.rememberSize(child)
+		}
+		slots := refresh.nodes
+		for _, fresh := range refresh.nodes {
+			if _, ok := m.watchOrig[fresh.Path]; !ok && existing[fresh.Path] == nil {
+				m.watchOrig[fresh.Path] = 0 // New since the scan
+			}
+		}
+		for _, path := range refresh.keep {
+			if child := existing[path]; child != nil {
+				slots = append(slots, child)
+			}
+		}
+		node.setChildren(slots)
+		node.Truncated = false
+		if len(node.Children) > maxTreeChildren {
+			node.Children = node.Children[:maxTreeChildren]
+			node.Truncated = true
+		}
+	}
+
+	// Ancestors take the difference, their other children are unchanged
+	for i := len(chain) - 2; i >= 0; i-- {
+		chain[i].applyChildDelta(before, node)
+	}
+}
+
+// rememberSize records the size a node had at scan time, before the first
+// change, for the "changed" badge.
+func (m *model) rememberSize(node *dirNode) {
+	if _, ok := m.watchOrig[node.Path]; !ok {
+		m.watchOrig[node.Path] = node.Size
+	}
+}
+
+// changedSince returns the size an entry had when scanned and whether it has
+// changed since, in watch mode.
+func (m model) changedSince(entry dirEntry) (int64, bool) {
+	if m.watch == nil {
+		return 0, false
+	}
+	orig, ok := m.watchOrig[entry.Path]
+	return orig, ok && orig != entry.Size
+}
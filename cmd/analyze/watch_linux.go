@@ -0,0 +1,162 @@
+//go:build linux
+
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR | syscall.IN_DONT_FOLLOW
+
+// dirWatcher reports directories whose listing or files changed, via inotify.
+type dirWatcher struct {
+	fd     int
+	file   *os.File
+	mu     sync.Mutex
+	wds    map[int32]string // Watch descriptor to directory
+	byPath map[string]int32
+	// Changes since the last next(), flagged on notify
+	pending  map[string]bool
+	overflow bool
+	notify   chan struct{}
+	done     chan struct{}
+}
+
+func newDirWatcher() (*dirWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &dirWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"), // Non-blocking, so Close interrupts Read
+		wds:     make(map[int32]string),
+		byPath:  make(map[string]int32),
+		pending: make(map[string]bool),
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	go w.read()
+	return w, nil
+}
+
+// setDirs replaces the watched directories and returns how many are watched.
+func (w *dirWatcher) setDirs(dirs []string) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wanted := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		wanted[dir] = true
+	}
+	for path, wd := range w.byPath {
+		if !wanted[path] {
+			_, _ = syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.byPath, path)
+			delete(w.wds, wd)
+		}
+	}
+	for _, dir := range dirs {
+		if _, ok := w.byPath[dir]; ok {
+			continue
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, dir, watchMask)
+		if err != nil {
+			// Gone, unreadable or out of watches; the rest may still work
+			continue
+		}
+		w.byPath[dir] = int32(wd)
+		w.wds[int32(wd)] = dir
+	}
+	return len(w.byPath)
+}
+
+func (w *dirWatcher) read() {
+	defer close(w.done)
+	buf := make([]byte, 64<<10)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		w.mu.Lock()
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			offset += syscall.SizeofInotifyEvent + int(event.Len)
+
+			switch {
+			case event.Mask&syscall.IN_Q_OVERFLOW != 0:
+				w.overflow = true
+			case event.Mask&syscall.IN_IGNORED != 0:
+				// The directory was deleted or unmounted; its parent reports it
+				if dir, ok := w.wds[event.Wd]; ok {
+					delete(w.byPath, dir)
+					delete(w.wds, event.Wd)
+				}
+			default:
+				if dir, ok := w.wds[event.Wd]; ok {
+					w.pending[dir] = true
+				}
+			}
+		}
+		changed := len(w.pending) > 0 || w.overflow
+		w.mu.Unlock()
+		if changed {
+			select {
+			case w.notify <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// next blocks until directories changed, then waits watchDebounce for the
+// burst to settle so a big copy is one update instead of thousands.
+func (w *dirWatcher) next() watchEventMsg {
+	select {
+	case <-w.notify:
+	case <-w.done:
+		return watchEventMsg{watcher: w, closed: true}
+	}
+	select {
+	case <-time.After(watchDebounce):
+	case <-w.done:
+		return watchEventMsg{watcher: w, closed: true}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	msg := watchEventMsg{watcher: w, overflow: w.overflow}
+	for dir := range w.pending {
+		msg.dirs = append(msg.dirs, dir)
+	}
+	w.pending = make(map[string]bool)
+	w.overflow = false
+	return msg
+}
+
+func (w *dirWatcher) close() {
+	_ = w.file.Close()
+}
+
+// watchLimit is the number of directories watch mode may use: maxWatchDirs,
+// or a quarter of the user's inotify watches if that is less, leaving the
+// rest to editors, IDEs and file managers.
+func watchLimit() int {
+	data, err := os.ReadFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		return maxWatchDirs
+	}
+	system, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || system/4 >= maxWatchDirs {
+		return maxWatchDirs
+	}
+	return system / 4
+}
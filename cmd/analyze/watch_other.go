@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// dirWatcher needs inotify; elsewhere watch mode reports it is unavailable.
+type dirWatcher struct{}
+
+func newDirWatcher() (*dirWatcher, error) {
+	return nil, errors.New("watch mode needs inotify and is only available on Linux")
+}
+
+func (w *dirWatcher) setDirs(dirs []string) int { return 0 }
+
+func (w *dirWatcher) next() watchEventMsg { return watchEventMsg{watcher: w, closed: true} }
+
+func (w *dirWatcher) close() {}
+
+func watchLimit() int { return 0 }
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/naiplawan/marmot/internal/clipboard"
+)
+
+// noticeDuration is how long the result of a copy stays on screen.
+const noticeDuration = 3 * time.Second
+
+type copiedMsg struct {
+	what   string
+	method string
+	err    error
+}
+
+// copyCmd puts text on the clipboard off the UI goroutine, since the
+// clipboard tools may wait on the display server.
+func copyCmd(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		method, err := clipboard.Copy(text)
+		return copiedMsg{what: what, method: method, err: err}
+	}
+}
+
+// copyKey returns the copy command for a key, or nil.
+func (m model) copyKey(key string) tea.Cmd {
+	if !m.ready {
+		return nil
+	}
+	switch key {
+	case "h":
+		return copyCmd("hostname", m.metrics.Host)
+	case "i":
+		ips := formatIPs(m.metrics.Network)
+		if ips == "" {
+			return nil
+		}
+		return copyCmd("IP addresses", ips)
+	case "c":
+		return copyCmd("status report", formatMetricsText(m.metrics))
+	}
+	return nil
+}
+
+// formatIPs lists the address of each interface that has one, one per line.
+func formatIPs(netStats []NetworkStatus) string {
+	var lines []string
+	for _, n := range netStats {
+		if n.IP != "" {
+			lines = append(lines, fmt.Sprintf("%s %s", n.Name, n.IP))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatMetricsText renders a snapshot as plain text, for pasting into an
+// issue or a chat without the colors and bars of the dashboard.
+func formatMetricsText(m MetricsSnapshot) string {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		fmt.Fprintf(&b, format+"\n", args...)
+	}
+
+	line("marmot status, %s", m.CollectedAt.Format("2006-01-02 15:04:05"))
+	line("Host      %s (%s)", m.Host, m.Platform)
+	if m.Hardware.Model != "" {
+		line("Model     %s", m.Hardware.Model)
+	}
+	if m.Hardware.OSVersion != "" {
+		line("OS        %s", m.Hardware.OSVersion)
+	}
+	line("Uptime    %s, %d processes", m.Uptime, m.Procs)
+	line("Health    %d (%s)", m.HealthScore, m.HealthScoreMsg)
+
+	line("")
+	line("CPU       %.1f%%, load %.2f %.2f %.2f, %d logical CPUs", m.CPU.Usage, m.CPU.Load1, m.CPU.Load5, m.CPU.Load15, m.CPU.LogicalCPU)
+	if m.Hardware.CPUModel != "" {
+		line("          %s", m.Hardware.CPUModel)
+	}
+	for _, g := range m.GPU {
+		line("GPU       %s, %.1f%%", g.Name, g.Usage)
+	}
+	line("Memory    %s / %s (%.1f%%)", humanBytes(m.Memory.Used), humanBytes(m.Memory.Total), m.Memory.UsedPercent)
+	if m.Memory.SwapTotal > 0 {
+		line("Swap      %s / %s", humanBytes(m.Memory.SwapUsed), humanBytes(m.Memory.SwapTotal))
+	}
+	if m.Memory.Pressure != "" {
+		line("Pressure  %s", m.Memory.Pressure)
+	}
+
+	if len(m.Disks) > 0 {
+		line("")
+		for _, d := range m.Disks {
+			line("Disk      %s %s / %s (%.1f%%), %s on %s", d.Mount, humanBytes(d.Used), humanBytes(d.Total), d.UsedPercent, d.Fstype, d.Device)
+		}
+		line("Disk I/O  read %.2f MB/s, write %.2f MB/s", m.DiskIO.ReadRate, m.DiskIO.WriteRate)
+	}
+
+	if len(m.Network) > 0 || m.Proxy.Enabled {
+		line("")
+		for _, n := range m.Network {
+			ip := n.IP
+			if ip == "" {
+				ip = "no address"
+			}
+			line("Network   %s %s, down %.2f MB/s, up %.2f MB/s", n.Name, ip, n.RxRateMBs, n.TxRateMBs)
+		}
+		if m.Proxy.Enabled {
+			line("Proxy     %s %s", m.Proxy.Type, m.Proxy.Host)
+		}
+	}
+
+	if len(m.Batteries) > 0 || m.Thermal.CPUTemp > 0 || m.Thermal.FanSpeed > 0 {
+		line("")
+		for _, batt := range m.Batteries {
+			line("Battery   %.0f%% %s, %s left, health %s, %d cycles", batt.Percent, batt.Status, batt.TimeLeft, batt.Health, batt.CycleCount)
+		}
+		if m.Thermal.CPUTemp > 0 {
+			line("CPU temp  %.1f°C", m.Thermal.CPUTemp)
+		}
+		if m.Thermal.GPUTemp > 0 {
+			line("GPU temp  %.1f°C", m.Thermal.GPUTemp)
+		}
+		if m.Thermal.FanSpeed > 0 {
+			line("Fans      %d RPM", m.Thermal.FanSpeed)
+		}
+	}
+	for _, s := range m.Sensors {
+		line("Sensor    %s %.1f%s", s.Label, s.Value, s.Unit)
+	}
+
+	if len(m.TopProcesses) > 0 {
+		line("")
+		for _, p := range m.TopProcesses {
+			line("Process   %s, CPU %.1f%%, memory %.1f%%", p.Name, p.CPU, p.Memory)
+		}
+	}
+	return b.String()
+}
+
+// renderFooter shows the copy keys, or the result of the last copy.
+func (m model) renderFooter() string {
+	if m.notice != "" && time.Now().Before(m.noticeUntil) {
+		if m.noticeErr {
+			return warnStyle.Render(m.notice)
+		}
+		return okStyle.Render(m.notice)
+	}
+	return subtleStyle.Render("h Host  ·  i IPs  ·  c Copy all  ·  q Quit")
+}
@@ -34,6 +34,9 @@ type model struct {
 	lastUpdated time.Time
 	collecting  bool
 	animFrame   int
+	notice      string
+	noticeErr   bool
+	noticeUntil time.Time
 }
 
 func newModel() model {
@@ -52,7 +55,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "q", "esc", "ctrl+c":
 			return m, tea.Quit
+		case "h", "i", "c":
+			return m, m.copyKey(msg.String())
 		}
+	case copiedMsg:
+		if msg.err != nil {
+			m.notice = fmt.Sprintf("Cannot copy the %s: %v", msg.what, msg.err)
+		} else {
+			m.notice = fmt.Sprintf("Copied the %s (%s)", msg.what, msg.method)
+		}
+		m.noticeErr = msg.err != nil
+		m.noticeUntil = time.Now().Add(noticeDuration)
+		return m, nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -101,10 +115,10 @@ func (m model) View() string {
 		for _, c := range cards {
 			rendered = append(rendered, renderCard(c, cardWidth, 0))
 		}
-		return header + "\n" + lipgloss.JoinVertical(lipgloss.Left, rendered...)
+		return header + "\n" + lipgloss.JoinVertical(lipgloss.Left, rendered...) + "\n" + m.renderFooter()
 	}
 
-	return header + "\n" + renderTwoColumns(cards, m.width)
+	return header + "\n" + renderTwoColumns(cards, m.width) + "\n" + m.renderFooter()
 }
 
 func (m model) collectCmd() tea.Cmd {
@@ -0,0 +1,113 @@
+// Package clipboard copies text to the clipboard from the marmot TUIs.
+//
+// OSC 52 is written to the controlling terminal, so copying works over SSH
+// and inside tmux or screen as long as the terminal supports it. When a local
+// clipboard tool is available (wl-copy, xclip, xsel or pbcopy) it is used as
+// well, for terminals that ignore OSC 52.
+package clipboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// toolTimeout bounds a clipboard tool; xclip waits for the X server.
+const toolTimeout = 3 * time.Second
+
+// tool is a command that reads the clipboard contents on stdin.
+type tool struct {
+	name string
+	args []string
+}
+
+// Copy puts text on the clipboard and returns how, e.g. "OSC 52 + wl-copy".
+// It fails only when no method could be used at all.
+func Copy(text string) (string, error) {
+	var methods []string
+	var errs []error
+
+	if err := copyOSC52(text); err == nil {
+		methods = append(methods, "OSC 52")
+	} else {
+		errs = append(errs, err)
+	}
+	if t, ok := localTool(); ok {
+		if err := t.copy(text); err == nil {
+			methods = append(methods, t.name)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(methods) == 0 {
+		if len(errs) == 0 {
+			return "", errors.New("no terminal or clipboard tool available")
+		}
+		return "", errors.Join(errs...)
+	}
+	return strings.Join(methods, " + "), nil
+}
+
+// copyOSC52 writes the sequence to /dev/tty rather than stdout, so it reaches
+// the terminal even while a TUI owns stdout.
+func copyOSC52(text string) error {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("no terminal: %w", err)
+	}
+	defer tty.Close()
+
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	_, err = seq.WriteTo(tty)
+	return err
+}
+
+// localTool picks the clipboard tool for the local display, if there is one.
+// Over SSH the display is the remote machine's, which OSC 52 already covers.
+func localTool() (tool, bool) {
+	var candidates []tool
+	switch {
+	case runtime.GOOS == "darwin":
+		if os.Getenv("SSH_CONNECTION") != "" {
+			return tool{}, false
+		}
+		candidates = []tool{{name: "pbcopy"}}
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = []tool{{name: "wl-copy"}, {name: "xclip", args: []string{"-selection", "clipboard"}}}
+	case os.Getenv("DISPLAY") != "":
+		candidates = []tool{{name: "xclip", args: []string{"-selection", "clipboard"}}, {name: "xsel", args: []string{"--clipboard", "--input"}}}
+	}
+	for _, t := range candidates {
+		if _, err := exec.LookPath(t.name); err == nil {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+func (t tool) copy(text string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), toolTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, t.name, t.args...)
+	// stdout stays unset: xclip and wl-copy fork to keep serving the
+	// selection, and a pipe would make Run wait for that child
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", t.name, err)
+	}
+	return nil
+}